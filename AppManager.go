@@ -4,6 +4,7 @@ import (
 	"GlideWay/apps/dirsearch"
 	"GlideWay/apps/gitdorker"
 	"GlideWay/apps/jsfinder"
+	"GlideWay/apps/pocscan"
 	"GlideWay/apps/portsscanner"
 	"context"
 )
@@ -17,6 +18,7 @@ func NewAppManager() *AppManager {
 			dirsearch.NewApp(),
 			gitdorker.NewApp(),
 			jsfinder.NewApp(),
+			pocscan.NewApp(),
 			// 在这里添加新的app即可(嘻嘻)*****
 		},
 	}
@@ -0,0 +1,165 @@
+package pocscan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+type App struct {
+	ctx context.Context
+}
+
+// NewApp 创建新的 App 实例
+func NewApp() *App {
+	return &App{}
+}
+
+// Startup 在应用启动时初始化上下文
+func (a *App) Startup(ctx context.Context) {
+	a.ctx = ctx
+}
+
+var (
+	currentPocScan *pocScanControl
+	pocScanMutex   sync.Mutex
+)
+
+// OpenTemplateFileDialog 选择单个模板文件
+func (a *App) OpenTemplateFileDialog() (string, error) {
+	options := runtime.OpenDialogOptions{
+		Title: "选择模板文件",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "YAML 模板 (*.yaml;*.yml)",
+				Pattern:     "*.yaml;*.yml",
+			},
+		},
+	}
+
+	return runtime.OpenFileDialog(a.ctx, options)
+}
+
+// OpenTemplateDirDialog 选择模板目录，目录下的模板会被递归加载
+func (a *App) OpenTemplateDirDialog() (string, error) {
+	return runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{Title: "选择模板目录"})
+}
+
+// LoadTemplates 加载并校验模板，返回可用模板与出错的模板
+func (a *App) LoadTemplates(path string) (*TemplateSummary, error) {
+	if path == "" {
+		return nil, fmt.Errorf("请选择模板文件或目录")
+	}
+	templates, errs := LoadTemplates(path)
+	summary := &TemplateSummary{Templates: templates}
+	for _, err := range errs {
+		summary.Errors = append(summary.Errors, err.Error())
+	}
+	return summary, nil
+}
+
+// StartPocScan 对目标执行模板
+func (a *App) StartPocScan(options PocScanOptions) error {
+	if a == nil || a.ctx == nil {
+		return fmt.Errorf("app context is not initialized")
+	}
+
+	templates, loadErrs := LoadTemplates(options.TemplatePath)
+	if len(templates) == 0 && len(loadErrs) > 0 {
+		return fmt.Errorf("模板加载失败: %s", joinErrors(loadErrs))
+	}
+	templates = FilterTemplates(templates, options.Severities, options.Tags)
+	if len(templates) == 0 {
+		return fmt.Errorf("没有符合条件的模板")
+	}
+	if len(options.Targets) == 0 {
+		return fmt.Errorf("请输入扫描目标")
+	}
+
+	pocScanMutex.Lock()
+	defer pocScanMutex.Unlock()
+
+	if currentPocScan != nil {
+		return fmt.Errorf("已有 PoC 扫描正在进行，请先停止")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	newScan := &pocScanControl{cancel: cancel}
+	currentPocScan = newScan
+
+	config := Config{
+		Threads: options.Threads,
+		Timeout: time.Duration(options.Timeout) * time.Millisecond,
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				runtime.EventsEmit(a.ctx, "poc-status", "error")
+				runtime.EventsEmit(a.ctx, "poc-error", fmt.Sprintf("PoC 扫描发生 panic: %v", r))
+			}
+			pocScanMutex.Lock()
+			if currentPocScan == newScan {
+				currentPocScan = nil
+			}
+			pocScanMutex.Unlock()
+		}()
+
+		runtime.EventsEmit(a.ctx, "poc-status", "running")
+		if len(loadErrs) > 0 {
+			// 部分模板无法解析时跳过这些模板，其余模板照常执行
+			runtime.EventsEmit(a.ctx, "poc-error", fmt.Sprintf("%d 个模板加载失败，已跳过: %s", len(loadErrs), joinErrors(loadErrs)))
+		}
+		err := Run(ctx, templates, options.Targets, config,
+			func(result Result) {
+				runtime.EventsEmit(a.ctx, "poc-result", result)
+			},
+			func(current, total int) {
+				runtime.EventsEmit(a.ctx, "poc-progress", PocScanProgress{Current: current, Total: total})
+			},
+			func(err error) {
+				runtime.EventsEmit(a.ctx, "poc-error", err.Error())
+			},
+		)
+
+		switch {
+		case err == context.Canceled:
+			runtime.EventsEmit(a.ctx, "poc-status", "cancelled")
+		case err != nil:
+			runtime.EventsEmit(a.ctx, "poc-status", "error")
+			runtime.EventsEmit(a.ctx, "poc-error", err.Error())
+		default:
+			runtime.EventsEmit(a.ctx, "poc-status", "completed")
+		}
+	}()
+
+	return nil
+}
+
+// joinErrors 拼接错误信息，最多列出前 3 个
+func joinErrors(errs []error) string {
+	var messages []string
+	for i, err := range errs {
+		if i == 3 {
+			messages = append(messages, fmt.Sprintf("等 %d 个", len(errs)))
+			break
+		}
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// StopPocScan 停止 PoC 扫描
+func (a *App) StopPocScan() error {
+	pocScanMutex.Lock()
+	defer pocScanMutex.Unlock()
+
+	if currentPocScan == nil {
+		return fmt.Errorf("no poc scan is running")
+	}
+	currentPocScan.cancel()
+	return nil
+}
@@ -0,0 +1,225 @@
+package pocscan
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Result 模板命中结果
+type Result struct {
+	TemplateID  string              `json:"template_id"`
+	Name        string              `json:"name"`
+	Severity    string              `json:"severity"`
+	Description string              `json:"description,omitempty"`
+	Reference   []string            `json:"reference,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Target      string              `json:"target"`
+	MatchedAt   string              `json:"matched_at"`          // 命中的 URL 或 host:port
+	Matchers    []string            `json:"matchers,omitempty"`  // 命中的具名匹配器
+	Extracted   map[string][]string `json:"extracted,omitempty"` // 非 internal 提取器的结果，按提取器名称
+	Timestamp   time.Time           `json:"timestamp"`
+}
+
+type ResultCallback func(Result)
+type ProgressCallback func(current, total int)
+type ErrorCallback func(error)
+
+// Config 扫描参数
+type Config struct {
+	Threads int
+	Timeout time.Duration
+}
+
+// FilterTemplates 按等级与标签筛选模板
+func FilterTemplates(templates []*Template, severities, tags []string) []*Template {
+	var result []*Template
+	for _, tmpl := range templates {
+		if len(severities) > 0 && !containsFold(severities, tmpl.Info.Severity) {
+			continue
+		}
+		if len(tags) > 0 {
+			matched := false
+			for _, tag := range tmpl.Info.Tags {
+				if containsFold(tags, tag) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		result = append(result, tmpl)
+	}
+	return result
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(strings.TrimSpace(item), s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Run 对每个目标执行全部模板，命中时通过 callback 上报，单个模板执行出错时通过 onError 上报
+func Run(ctx context.Context, templates []*Template, targets []string, config Config, callback ResultCallback, progress ProgressCallback, onError ErrorCallback) error {
+	if callback == nil {
+		return fmt.Errorf("callback function cannot be nil")
+	}
+	if len(templates) == 0 {
+		return fmt.Errorf("没有可执行的模板")
+	}
+	if config.Threads <= 0 {
+		config.Threads = 20
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+
+	var parsed []Target
+	seen := make(map[string]bool)
+	for _, input := range targets {
+		target, err := ParseTarget(input)
+		if err != nil || seen[target.Input] {
+			continue
+		}
+		seen[target.Input] = true
+		parsed = append(parsed, target)
+	}
+	if len(parsed) == 0 {
+		return fmt.Errorf("没有有效的目标")
+	}
+
+	e := &executor{
+		client:   newHTTPClient(config.Timeout),
+		timeout:  config.Timeout,
+		callback: callback,
+		onError:  onError,
+	}
+
+	type job struct {
+		target Target
+		tmpl   *Template
+	}
+	jobs := make(chan job)
+	total := len(parsed) * len(templates)
+	var done int32
+
+	var wg sync.WaitGroup
+	for i := 0; i < config.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				e.safeExecute(ctx, j.tmpl, j.target)
+				if progress != nil {
+					progress(int(atomic.AddInt32(&done, 1)), total)
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, target := range parsed {
+		for _, tmpl := range templates {
+			select {
+			case <-ctx.Done():
+				break feed
+			case jobs <- job{target, tmpl}:
+			}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return context.Canceled
+	}
+	return nil
+}
+
+type executor struct {
+	client   *http.Client
+	timeout  time.Duration
+	callback ResultCallback
+	onError  ErrorCallback
+}
+
+// safeExecute 执行单个模板，模板导致的 panic 不影响其他模板与整个应用
+func (e *executor) safeExecute(ctx context.Context, tmpl *Template, target Target) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e.onError != nil {
+				e.onError(fmt.Errorf("模板 %s 执行时发生 panic: %v", tmpl.ID, r))
+			}
+		}
+	}()
+	e.execute(ctx, tmpl, target)
+}
+
+// execute 对单个目标执行模板中的全部请求，变量在同一次执行内共享
+func (e *executor) execute(ctx context.Context, tmpl *Template, target Target) {
+	vars := target.variables()
+	for key, value := range tmpl.Variables {
+		vars[key] = expandVariables(value, vars)
+	}
+
+	for _, req := range tmpl.HTTP {
+		e.executeHTTP(ctx, tmpl, req, target, vars)
+	}
+	for _, req := range tmpl.Network {
+		e.executeNetwork(ctx, tmpl, req, target, vars)
+	}
+}
+
+// evaluate 运行提取器与匹配器，命中时上报结果
+func (e *executor) evaluate(tmpl *Template, target Target, matchers []*Matcher, condition string, extractors []*Extractor, resp *response, vars map[string]string, matchedAt string) bool {
+	extracted := make(map[string][]string)
+	for i, extractor := range extractors {
+		values := extractor.extract(resp)
+		if len(values) == 0 {
+			continue
+		}
+		if extractor.Internal {
+			if extractor.Name != "" {
+				vars[extractor.Name] = values[0]
+			}
+			continue
+		}
+		name := extractor.Name
+		if name == "" {
+			name = fmt.Sprintf("extractor-%d", i)
+		}
+		extracted[name] = append(extracted[name], values...)
+	}
+
+	matched, names := matchAll(matchers, strings.ToLower(condition), resp, vars)
+	if !matched {
+		return false
+	}
+
+	result := Result{
+		TemplateID:  tmpl.ID,
+		Name:        tmpl.Info.Name,
+		Severity:    tmpl.Info.Severity,
+		Description: strings.TrimSpace(tmpl.Info.Description),
+		Reference:   tmpl.Info.Reference,
+		Tags:        tmpl.Info.Tags,
+		Target:      target.Input,
+		MatchedAt:   matchedAt,
+		Matchers:    names,
+		Timestamp:   time.Now(),
+	}
+	if len(extracted) > 0 {
+		result.Extracted = extracted
+	}
+	e.callback(result)
+	return true
+}
@@ -0,0 +1,183 @@
+package pocscan
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	maxResponseSize     = 4 << 20
+	defaultMaxRedirects = 10
+)
+
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
+			MaxIdleConnsPerHost: 4,
+		},
+	}
+}
+
+// executeHTTP 依次发送 path 或 raw 中的请求，每个响应单独匹配，
+// internal 提取器的结果作为变量供后续请求使用
+func (e *executor) executeHTTP(ctx context.Context, tmpl *Template, req *HTTPRequest, target Target, vars map[string]string) {
+	items := req.Path
+	if len(req.Raw) > 0 {
+		items = req.Raw
+	}
+
+	for _, item := range items {
+		if ctx.Err() != nil {
+			return
+		}
+		var (
+			httpReq *http.Request
+			err     error
+		)
+		if len(req.Raw) > 0 {
+			httpReq, err = buildRawRequest(ctx, expandVariables(item, vars), vars["RootURL"])
+		} else {
+			httpReq, err = buildRequest(ctx, req, expandVariables(item, vars), vars)
+		}
+		if err != nil {
+			continue
+		}
+
+		resp, err := e.doHTTP(req, httpReq)
+		if err != nil {
+			continue
+		}
+		if e.evaluate(tmpl, target, req.Matchers, req.MatchersCondition, req.Extractors, resp, vars, httpReq.URL.String()) && req.StopAtFirstMatch {
+			return
+		}
+	}
+}
+
+func buildRequest(ctx context.Context, req *HTTPRequest, target string, vars map[string]string) (*http.Request, error) {
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(expandVariables(req.Body, vars))
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("User-Agent", defaultUserAgent)
+	for key, value := range req.Headers {
+		value = expandVariables(value, vars)
+		if strings.EqualFold(key, "Host") {
+			httpReq.Host = value
+			continue
+		}
+		httpReq.Header.Set(key, value)
+	}
+	return httpReq, nil
+}
+
+const defaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36"
+
+// buildRawRequest 解析原始 HTTP 请求，请求行中的路径拼接在 rootURL 之后
+func buildRawRequest(ctx context.Context, raw, rootURL string) (*http.Request, error) {
+	raw = strings.TrimLeft(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	head, body, _ := strings.Cut(raw, "\n\n")
+	reader := bufio.NewReader(strings.NewReader(head))
+
+	requestLine, _ := reader.ReadString('\n')
+	fields := strings.Fields(requestLine)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("无效的请求行: %q", requestLine)
+	}
+	method, path := fields[0], fields[1]
+
+	target := path
+	if !strings.Contains(path, "://") {
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		target = rootURL + path
+	}
+	if _, err := url.Parse(target); err != nil {
+		return nil, err
+	}
+
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		line, err := reader.ReadString('\n')
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), ":"); ok {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			switch {
+			case strings.EqualFold(key, "Host"):
+				req.Host = value
+			case strings.EqualFold(key, "Content-Length"):
+				// 由实际请求体重新计算
+			default:
+				req.Header.Add(key, value)
+			}
+		}
+		if err != nil {
+			break
+		}
+	}
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", defaultUserAgent)
+	}
+	return req, nil
+}
+
+func (e *executor) doHTTP(req *HTTPRequest, httpReq *http.Request) (*response, error) {
+	client := *e.client
+	maxRedirects := req.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		switch {
+		case !req.Redirects && !req.HostRedirects:
+			return http.ErrUseLastResponse
+		case req.HostRedirects && !req.Redirects && next.URL.Host != via[0].URL.Host:
+			// host-redirects 只跟随同一主机内的跳转
+			return http.ErrUseLastResponse
+		case len(via) > maxRedirects:
+			return http.ErrUseLastResponse
+		}
+		return nil
+	}
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+
+	var header strings.Builder
+	fmt.Fprintf(&header, "%s %s\r\n", resp.Proto, resp.Status)
+	_ = resp.Header.Write(&header)
+
+	return &response{
+		status: resp.StatusCode,
+		header: resp.Header,
+		parts: map[string]string{
+			"body":   string(body),
+			"header": header.String(),
+			"all":    header.String() + "\r\n" + string(body),
+		},
+		defaultPart: "body",
+	}, nil
+}
@@ -0,0 +1,140 @@
+package pocscan
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// response 一次请求的结果，匹配器与提取器按 part 名称取值
+type response struct {
+	status      int
+	header      http.Header
+	parts       map[string]string
+	defaultPart string // 未指定 part 时使用，HTTP 为 body，网络请求为 data
+}
+
+func (r *response) part(name string) string {
+	if name == "" {
+		name = r.defaultPart
+	}
+	switch name {
+	case "response", "raw":
+		name = "all"
+	case "headers":
+		name = "header"
+	}
+	return r.parts[name]
+}
+
+// matchAll 按 matchers-condition(默认 or)组合匹配结果，返回是否命中及命中的匹配器名称
+func matchAll(matchers []*Matcher, condition string, r *response, vars map[string]string) (bool, []string) {
+	var names []string
+	for _, m := range matchers {
+		ok := m.match(r, vars)
+		if condition == "and" && !ok {
+			return false, nil
+		}
+		if ok {
+			if m.Name != "" {
+				names = append(names, m.Name)
+			}
+			if condition != "and" {
+				return true, names
+			}
+		}
+	}
+	return condition == "and", names
+}
+
+func (m *Matcher) match(r *response, vars map[string]string) bool {
+	var ok bool
+	switch m.Type {
+	case "status":
+		for _, status := range m.Status {
+			if status == r.status {
+				ok = true
+				break
+			}
+		}
+	case "size":
+		size := len(r.part(m.Part))
+		for _, want := range m.Size {
+			if want == size {
+				ok = true
+				break
+			}
+		}
+	case "word":
+		data := r.part(m.Part)
+		if m.CaseInsensitive {
+			data = strings.ToLower(data)
+		}
+		ok = m.combine(len(m.Words), func(i int) bool {
+			word := expandVariables(m.Words[i], vars)
+			if m.CaseInsensitive {
+				word = strings.ToLower(word)
+			}
+			return strings.Contains(data, word)
+		})
+	case "regex":
+		data := r.part(m.Part)
+		ok = m.combine(len(m.regexps), func(i int) bool {
+			return m.regexps[i].MatchString(data)
+		})
+	case "binary":
+		data := []byte(r.part(m.Part))
+		ok = m.combine(len(m.binary), func(i int) bool {
+			return bytes.Contains(data, m.binary[i])
+		})
+	}
+
+	if m.Negative {
+		return !ok
+	}
+	return ok
+}
+
+// combine 按 condition(默认 or)组合同一匹配器内的多个条件
+func (m *Matcher) combine(n int, test func(i int) bool) bool {
+	for i := 0; i < n; i++ {
+		ok := test(i)
+		if m.Condition == "and" && !ok {
+			return false
+		}
+		if m.Condition != "and" && ok {
+			return true
+		}
+	}
+	return m.Condition == "and"
+}
+
+// extract 返回提取到的去重结果
+func (e *Extractor) extract(r *response) []string {
+	var values []string
+	seen := make(map[string]bool)
+	add := func(value string) {
+		if value != "" && !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+	}
+
+	switch e.Type {
+	case "regex":
+		data := r.part(e.Part)
+		for _, re := range e.regexps {
+			for _, match := range re.FindAllStringSubmatch(data, -1) {
+				add(match[e.Group])
+			}
+		}
+	case "kval":
+		// 与 nuclei 一致，响应头名中的 - 写作 _，如 content_type
+		for _, key := range e.KVal {
+			if r.header != nil {
+				add(r.header.Get(strings.ReplaceAll(key, "_", "-")))
+			}
+		}
+	}
+	return values
+}
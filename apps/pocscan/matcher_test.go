@@ -0,0 +1,153 @@
+package pocscan
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func testResponse() *response {
+	header := http.Header{}
+	header.Set("Server", "nginx/1.18.0")
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	body := "<html><title>Admin Login</title><p>version: 2.4.1</p></html>"
+	return &response{
+		status: 200,
+		header: header,
+		parts: map[string]string{
+			"body":   body,
+			"header": "Server: nginx/1.18.0\r\nContent-Type: text/html; charset=utf-8\r\n",
+			"all":    "HTTP/1.1 200 OK\r\nServer: nginx/1.18.0\r\n\r\n" + body,
+			"banner": "\x00\x01\xffRFB 003.008\n",
+		},
+		defaultPart: "body",
+	}
+}
+
+func parseMatcher(t *testing.T, data string) *Matcher {
+	t.Helper()
+	var m Matcher
+	if err := yaml.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("invalid matcher %q: %v", data, err)
+	}
+	if err := m.compile(); err != nil {
+		t.Fatalf("matcher %q does not compile: %v", data, err)
+	}
+	return &m
+}
+
+func TestMatcherMatch(t *testing.T) {
+	vars := map[string]string{"Hostname": "example.com", "marker": "Admin"}
+	tests := []struct {
+		matcher string
+		want    bool
+	}{
+		{"{type: status, status: [200, 302]}", true},
+		{"{type: status, status: [404]}", false},
+		{"{type: status, status: [200], negative: true}", false},
+		{"{type: size, size: [60]}", true},
+		{"{type: size, size: [10, 20]}", false},
+		{"{type: size, part: banner, size: [15]}", true},
+		{"{type: word, words: [Admin Login]}", true},
+		{"{type: word, words: [admin login]}", false},
+		{"{type: word, words: [admin login], case-insensitive: true}", true},
+		{"{type: word, words: [Admin, missing]}", true},
+		{"{type: word, words: [Admin, missing], condition: and}", false},
+		{"{type: word, words: [Admin, '2.4.1'], condition: and}", true},
+		{"{type: word, words: [nginx], part: header}", true},
+		{"{type: word, words: [nginx], part: headers}", true},
+		{"{type: word, words: [nginx]}", false},
+		{"{type: word, words: ['HTTP/1.1 200', Admin], part: response, condition: and}", true},
+		{"{type: word, words: ['{{marker}} Login']}", true},
+		{"{type: word, words: [Admin], negative: true}", false},
+		{"{type: word, words: [Forbidden], negative: true}", true},
+		{"{type: regex, regex: ['version: 2\\.[0-9]+\\.[0-9]+']}", true},
+		{"{type: regex, regex: ['<TITLE>admin']}", false},
+		{"{type: regex, regex: ['<TITLE>admin'], case-insensitive: true}", true},
+		{"{type: regex, regex: ['^HTTP/1\\.1 200', 'nope'], part: all, condition: and}", false},
+		{"{type: binary, binary: ['524642'], part: banner}", true},
+		{"{type: binary, binary: ['00ff'], part: banner}", false},
+		{"{type: binary, binary: ['0001ff', '4141'], part: banner, condition: and}", false},
+	}
+	r := testResponse()
+	for _, tt := range tests {
+		if got := parseMatcher(t, tt.matcher).match(r, vars); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.matcher, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAll(t *testing.T) {
+	matchers := []*Matcher{
+		parseMatcher(t, "{type: status, name: ok, status: [200]}"),
+		parseMatcher(t, "{type: word, name: login, words: [Login]}"),
+		parseMatcher(t, "{type: word, name: missing, words: [missing]}"),
+	}
+	tests := []struct {
+		name      string
+		matchers  []*Matcher
+		condition string
+		want      bool
+		names     []string
+	}{
+		{"or stops at the first match", matchers, "", true, []string{"ok"}},
+		{"or without any match", matchers[2:], "or", false, nil},
+		{"and with a failing matcher", matchers, "and", false, nil},
+		{"and reports every name", matchers[:2], "and", true, []string{"ok", "login"}},
+	}
+	r := testResponse()
+	for _, tt := range tests {
+		got, names := matchAll(tt.matchers, tt.condition, r, nil)
+		if got != tt.want || !slices.Equal(names, tt.names) {
+			t.Errorf("%s: matchAll = %v %v, want %v %v", tt.name, got, names, tt.want, tt.names)
+		}
+	}
+}
+
+func TestMatcherCompileErrors(t *testing.T) {
+	for _, data := range []string{
+		"{type: status}",
+		"{type: size}",
+		"{type: word}",
+		"{type: regex, regex: ['(']}",
+		"{type: binary, binary: [zz]}",
+		"{type: dsl, dsl: ['true']}",
+		"{type: word, words: [a], condition: xor}",
+	} {
+		var m Matcher
+		if err := yaml.Unmarshal([]byte(data), &m); err != nil {
+			t.Fatalf("invalid matcher %q: %v", data, err)
+		}
+		if err := m.compile(); err == nil {
+			t.Errorf("%s compiled without error", data)
+		}
+	}
+}
+
+func TestExtractorExtract(t *testing.T) {
+	tests := []struct {
+		extractor string
+		want      []string
+	}{
+		{"{type: regex, regex: ['version: ([0-9.]+)'], group: 1}", []string{"2.4.1"}},
+		{"{type: regex, regex: ['<title>([^<]+)</title>', '<p>([^<]+)</p>'], group: 1}", []string{"Admin Login", "version: 2.4.1"}},
+		{"{type: regex, part: header, regex: ['nginx/[0-9.]+', 'nginx/[0-9.]+']}", []string{"nginx/1.18.0"}},
+		{"{type: regex, regex: ['missing ([0-9]+)'], group: 1}", nil},
+		{"{type: kval, kval: [server, content_type, x_missing]}", []string{"nginx/1.18.0", "text/html; charset=utf-8"}},
+	}
+	r := testResponse()
+	for _, tt := range tests {
+		var e Extractor
+		if err := yaml.Unmarshal([]byte(tt.extractor), &e); err != nil {
+			t.Fatalf("invalid extractor %q: %v", tt.extractor, err)
+		}
+		if err := e.compile(); err != nil {
+			t.Fatalf("extractor %q does not compile: %v", tt.extractor, err)
+		}
+		if got := e.extract(r); !slices.Equal(got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.extractor, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package pocscan
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"strings"
+	"time"
+)
+
+const defaultReadSize = 1024
+
+// executeNetwork 对 host 中的每个地址建立连接，按顺序发送 inputs 后匹配收到的全部数据
+func (e *executor) executeNetwork(ctx context.Context, tmpl *Template, req *NetworkRequest, target Target, vars map[string]string) {
+	for _, host := range req.Host {
+		address := expandVariables(host, vars)
+		useTLS := false
+		if rest, ok := strings.CutPrefix(address, "tls://"); ok {
+			address, useTLS = rest, true
+		}
+		for _, addr := range networkAddresses(address, target, req.Port) {
+			if ctx.Err() != nil {
+				return
+			}
+			resp, err := e.exchange(ctx, addr, useTLS, req, vars)
+			if err != nil {
+				continue
+			}
+			e.evaluate(tmpl, target, req.Matchers, req.MatchersCondition, req.Extractors, resp, vars, addr)
+		}
+	}
+}
+
+// networkAddresses 返回需要连接的地址，目标未指定端口时与 nuclei 一致依次使用模板 port 中的每个端口
+func networkAddresses(address string, target Target, ports string) []string {
+	if target.Port != "" || ports == "" {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return nil
+		}
+		return []string{address}
+	}
+
+	var addresses []string
+	for _, port := range strings.Split(ports, ",") {
+		if port = strings.TrimSpace(port); port == "" {
+			continue
+		}
+		addresses = append(addresses, net.JoinHostPort(strings.Trim(address, "[]"), port))
+	}
+	return addresses
+}
+
+func (e *executor) exchange(ctx context.Context, address string, useTLS bool, req *NetworkRequest, vars map[string]string) (*response, error) {
+	dialer := &net.Dialer{Timeout: e.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if useTLS {
+		conn = tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(e.timeout))
+
+	resp := &response{parts: make(map[string]string), defaultPart: "data"}
+	var all bytes.Buffer
+	for _, input := range req.Inputs {
+		data := []byte(expandVariables(input.Data, vars))
+		if input.Type == "hex" {
+			if data, err = hex.DecodeString(string(data)); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := conn.Write(data); err != nil {
+			return nil, err
+		}
+		if input.Read > 0 {
+			chunk := readSome(conn, input.Read, e.timeout)
+			all.Write(chunk)
+			if input.Name != "" {
+				resp.parts[input.Name] = string(chunk)
+			}
+		}
+	}
+
+	readSize := req.ReadSize
+	if readSize <= 0 {
+		readSize = defaultReadSize
+	}
+	all.Write(readSome(conn, readSize, e.timeout))
+
+	resp.parts["data"] = all.String()
+	resp.parts["body"] = all.String()
+	resp.parts["all"] = all.String()
+	return resp, nil
+}
+
+// readSome 最多读取 size 字节，收到数据后短暂等待后续分片
+func readSome(conn net.Conn, size int, timeout time.Duration) []byte {
+	buf := make([]byte, size)
+	total := 0
+	deadline := time.Now().Add(timeout)
+	for total < size {
+		_ = conn.SetReadDeadline(deadline)
+		n, err := conn.Read(buf[total:])
+		total += n
+		if err != nil {
+			break
+		}
+		// 已收到数据时只再等待 300ms
+		if wait := time.Now().Add(300 * time.Millisecond); wait.Before(deadline) {
+			deadline = wait
+		}
+	}
+	return buf[:total]
+}
@@ -0,0 +1,28 @@
+package pocscan
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNetworkAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		target  Target
+		ports   string
+		want    []string
+	}{
+		{"target port", "10.0.0.1:6379", Target{Host: "10.0.0.1", Port: "6379"}, "6380", []string{"10.0.0.1:6379"}},
+		{"single template port", "10.0.0.1", Target{Host: "10.0.0.1"}, "6379", []string{"10.0.0.1:6379"}},
+		{"every template port", "10.0.0.1", Target{Host: "10.0.0.1"}, "6379, 6380,,7000", []string{"10.0.0.1:6379", "10.0.0.1:6380", "10.0.0.1:7000"}},
+		{"ipv6 host", "[2001:db8::1]", Target{Host: "2001:db8::1"}, "22,2222", []string{"[2001:db8::1]:22", "[2001:db8::1]:2222"}},
+		{"no port anywhere", "10.0.0.1", Target{Host: "10.0.0.1"}, "", nil},
+		{"host with port and no template port", "example.com:23", Target{Host: "example.com"}, "", []string{"example.com:23"}},
+	}
+	for _, tt := range tests {
+		if got := networkAddresses(tt.address, tt.target, tt.ports); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: networkAddresses = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package pocscan
+
+import "context"
+
+// PocScanOptions 前端传入的扫描参数
+type PocScanOptions struct {
+	TemplatePath string   `json:"template_path"` // 模板文件或目录
+	Targets      []string `json:"targets"`       // URL 或 host:port，可来自端口扫描与目录扫描结果
+	Threads      int      `json:"threads"`
+	Timeout      int      `json:"timeout"`    // 单个请求超时(毫秒)
+	Severities   []string `json:"severities"` // 只执行这些等级的模板
+	Tags         []string `json:"tags"`       // 只执行包含任一标签的模板
+}
+
+// TemplateSummary 模板加载结果
+type TemplateSummary struct {
+	Templates []*Template `json:"templates"`
+	Errors    []string    `json:"errors"`
+}
+
+type PocScanProgress struct {
+	Current int `json:"current"`
+	Total   int `json:"total"`
+}
+
+type pocScanControl struct {
+	cancel context.CancelFunc
+}
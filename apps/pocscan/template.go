@@ -0,0 +1,348 @@
+package pocscan
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 风险等级，与 nuclei 一致
+var severities = map[string]bool{
+	"info": true, "low": true, "medium": true, "high": true, "critical": true, "unknown": true,
+}
+
+// Template PoC 模板，字段为 nuclei 模板格式的子集，详见 TEMPLATES.md
+type Template struct {
+	ID        string            `yaml:"id" json:"id"`
+	Info      TemplateInfo      `yaml:"info" json:"info"`
+	Variables map[string]string `yaml:"variables" json:"-"`
+	HTTP      []*HTTPRequest    `yaml:"http" json:"-"`
+	Network   []*NetworkRequest `yaml:"network" json:"-"`
+
+	// 旧版 nuclei 模板使用的字段名
+	Requests []*HTTPRequest    `yaml:"requests" json:"-"`
+	TCP      []*NetworkRequest `yaml:"tcp" json:"-"`
+
+	Path string `yaml:"-" json:"path"`
+}
+
+type TemplateInfo struct {
+	Name        string     `yaml:"name" json:"name"`
+	Author      stringList `yaml:"author" json:"author"`
+	Severity    string     `yaml:"severity" json:"severity"`
+	Description string     `yaml:"description" json:"description"`
+	Reference   stringList `yaml:"reference" json:"reference"`
+	Tags        stringList `yaml:"tags" json:"tags"`
+}
+
+// HTTPRequest 一组 HTTP 请求，path 与 raw 二选一
+type HTTPRequest struct {
+	Method            string            `yaml:"method"`
+	Path              textList          `yaml:"path"`
+	Raw               textList          `yaml:"raw"`
+	Headers           map[string]string `yaml:"headers"`
+	Body              string            `yaml:"body"`
+	Redirects         bool              `yaml:"redirects"`
+	HostRedirects     bool              `yaml:"host-redirects"`
+	MaxRedirects      int               `yaml:"max-redirects"`
+	StopAtFirstMatch  bool              `yaml:"stop-at-first-match"`
+	MatchersCondition string            `yaml:"matchers-condition"`
+	Matchers          []*Matcher        `yaml:"matchers"`
+	Extractors        []*Extractor      `yaml:"extractors"`
+}
+
+// NetworkRequest 一组 TCP 交互，按顺序发送 inputs 并读取响应
+type NetworkRequest struct {
+	Host              textList       `yaml:"host"`
+	Port              string         `yaml:"port"` // 目标未指定端口时使用，可为逗号分隔的多个端口，依次连接
+	Inputs            []NetworkInput `yaml:"inputs"`
+	ReadSize          int            `yaml:"read-size"`
+	MatchersCondition string         `yaml:"matchers-condition"`
+	Matchers          []*Matcher     `yaml:"matchers"`
+	Extractors        []*Extractor   `yaml:"extractors"`
+}
+
+type NetworkInput struct {
+	Data string `yaml:"data"`
+	Type string `yaml:"type"` // 为 hex 时 data 为十六进制编码
+	Read int    `yaml:"read"` // 发送后读取的字节数，0 表示不单独读取
+	Name string `yaml:"name"` // 该次读取结果可在匹配器中以 part 引用
+}
+
+// Matcher 匹配器
+type Matcher struct {
+	Type            string   `yaml:"type"` // status / word / regex / binary / size
+	Name            string   `yaml:"name"`
+	Part            string   `yaml:"part"`
+	Condition       string   `yaml:"condition"` // and / or，默认 or
+	Negative        bool     `yaml:"negative"`
+	CaseInsensitive bool     `yaml:"case-insensitive"`
+	Status          []int    `yaml:"status"`
+	Size            []int    `yaml:"size"`
+	Words           textList `yaml:"words"`
+	Regex           textList `yaml:"regex"`
+	Binary          textList `yaml:"binary"`
+
+	regexps []*regexp.Regexp
+	binary  [][]byte
+}
+
+// Extractor 提取器，internal 为 true 时提取结果只作为后续请求的变量，不出现在结果中
+type Extractor struct {
+	Type     string     `yaml:"type"` // regex / kval
+	Name     string     `yaml:"name"`
+	Part     string     `yaml:"part"`
+	Regex    textList   `yaml:"regex"`
+	Group    int        `yaml:"group"`
+	KVal     stringList `yaml:"kval"`
+	Internal bool       `yaml:"internal"`
+
+	regexps []*regexp.Regexp
+}
+
+// stringList 兼容 YAML 中的单个字符串、逗号分隔字符串与字符串列表
+type stringList []string
+
+func (s *stringList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		for _, item := range strings.Split(node.Value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*s = append(*s, item)
+			}
+		}
+		return nil
+	case yaml.SequenceNode:
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		*s = items
+		return nil
+	}
+	return fmt.Errorf("第 %d 行: 应为字符串或字符串列表", node.Line)
+}
+
+// textList 兼容单个字符串与字符串列表，单个字符串不拆分，用于 raw、words、regex 等可能含逗号的字段
+type textList []string
+
+func (s *textList) UnmarshalYAML(node *yaml.Node) error {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		*s = textList{node.Value}
+		return nil
+	case yaml.SequenceNode:
+		var items []string
+		if err := node.Decode(&items); err != nil {
+			return err
+		}
+		*s = items
+		return nil
+	}
+	return fmt.Errorf("第 %d 行: 应为字符串或字符串列表", node.Line)
+}
+
+// ParseTemplate 解析并校验单个模板
+func ParseTemplate(data []byte) (*Template, error) {
+	var tmpl Template
+	if err := yaml.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("YAML 格式错误: %w", err)
+	}
+	tmpl.HTTP = append(tmpl.HTTP, tmpl.Requests...)
+	tmpl.Network = append(tmpl.Network, tmpl.TCP...)
+	tmpl.Requests, tmpl.TCP = nil, nil
+
+	if err := tmpl.compile(); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// LoadTemplates 加载单个模板文件或目录下的全部 .yaml / .yml 模板，
+// 无法解析的模板记录在错误列表中，不影响其余模板
+func LoadTemplates(path string) ([]*Template, []error) {
+	var templates []*Template
+	var errs []error
+
+	err := filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(file))
+		if d.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			return nil
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			return nil
+		}
+		tmpl, err := ParseTemplate(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file, err))
+			return nil
+		}
+		tmpl.Path = file
+		templates = append(templates, tmpl)
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return templates, errs
+}
+
+// compile 校验模板并预编译正则与二进制匹配内容
+func (t *Template) compile() error {
+	if t.ID == "" {
+		return fmt.Errorf("缺少 id")
+	}
+	if t.Info.Name == "" {
+		t.Info.Name = t.ID
+	}
+	t.Info.Severity = strings.ToLower(t.Info.Severity)
+	if t.Info.Severity == "" {
+		t.Info.Severity = "unknown"
+	}
+	if !severities[t.Info.Severity] {
+		return fmt.Errorf("未知的 severity: %s", t.Info.Severity)
+	}
+	if len(t.HTTP) == 0 && len(t.Network) == 0 {
+		return fmt.Errorf("模板 %s 没有 http 或 network 请求", t.ID)
+	}
+
+	for i, req := range t.HTTP {
+		if len(req.Path) == 0 && len(req.Raw) == 0 {
+			return fmt.Errorf("http[%d] 缺少 path 或 raw", i)
+		}
+		if req.Method == "" {
+			req.Method = "GET"
+		}
+		req.Method = strings.ToUpper(req.Method)
+		if err := compileOperators(req.Matchers, req.Extractors); err != nil {
+			return fmt.Errorf("http[%d]: %w", i, err)
+		}
+	}
+	for i, req := range t.Network {
+		if len(req.Host) == 0 {
+			req.Host = textList{"{{Hostname}}"}
+		}
+		if len(req.Inputs) == 0 {
+			return fmt.Errorf("network[%d] 缺少 inputs", i)
+		}
+		for j, input := range req.Inputs {
+			if input.Type == "hex" {
+				if _, err := hex.DecodeString(input.Data); err != nil {
+					return fmt.Errorf("network[%d].inputs[%d] 不是有效的十六进制: %w", i, j, err)
+				}
+			}
+		}
+		if err := compileOperators(req.Matchers, req.Extractors); err != nil {
+			return fmt.Errorf("network[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func compileOperators(matchers []*Matcher, extractors []*Extractor) error {
+	if len(matchers) == 0 {
+		return fmt.Errorf("至少需要一个 matcher")
+	}
+	for i, m := range matchers {
+		if err := m.compile(); err != nil {
+			return fmt.Errorf("matchers[%d]: %w", i, err)
+		}
+	}
+	for i, e := range extractors {
+		if err := e.compile(); err != nil {
+			return fmt.Errorf("extractors[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (m *Matcher) compile() error {
+	m.Condition = strings.ToLower(m.Condition)
+	if m.Condition != "" && m.Condition != "and" && m.Condition != "or" {
+		return fmt.Errorf("未知的 condition: %s", m.Condition)
+	}
+
+	switch m.Type {
+	case "status":
+		if len(m.Status) == 0 {
+			return fmt.Errorf("status 匹配器缺少 status")
+		}
+	case "size":
+		if len(m.Size) == 0 {
+			return fmt.Errorf("size 匹配器缺少 size")
+		}
+	case "word":
+		if len(m.Words) == 0 {
+			return fmt.Errorf("word 匹配器缺少 words")
+		}
+	case "regex":
+		if len(m.Regex) == 0 {
+			return fmt.Errorf("regex 匹配器缺少 regex")
+		}
+		for _, expr := range m.Regex {
+			if m.CaseInsensitive {
+				expr = "(?i)" + expr
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("正则 %q 无效: %w", expr, err)
+			}
+			m.regexps = append(m.regexps, re)
+		}
+	case "binary":
+		if len(m.Binary) == 0 {
+			return fmt.Errorf("binary 匹配器缺少 binary")
+		}
+		for _, item := range m.Binary {
+			data, err := hex.DecodeString(item)
+			if err != nil {
+				return fmt.Errorf("binary %q 不是有效的十六进制: %w", item, err)
+			}
+			m.binary = append(m.binary, data)
+		}
+	default:
+		return fmt.Errorf("不支持的匹配器类型: %s", m.Type)
+	}
+	return nil
+}
+
+func (e *Extractor) compile() error {
+	switch e.Type {
+	case "regex":
+		if len(e.Regex) == 0 {
+			return fmt.Errorf("regex 提取器缺少 regex")
+		}
+		if e.Group < 0 {
+			return fmt.Errorf("group 不能为负数: %d", e.Group)
+		}
+		for _, expr := range e.Regex {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return fmt.Errorf("正则 %q 无效: %w", expr, err)
+			}
+			if e.Group > re.NumSubexp() {
+				return fmt.Errorf("正则 %q 没有第 %d 个分组", expr, e.Group)
+			}
+			e.regexps = append(e.regexps, re)
+		}
+	case "kval":
+		if len(e.KVal) == 0 {
+			return fmt.Errorf("kval 提取器缺少 kval")
+		}
+	default:
+		return fmt.Errorf("不支持的提取器类型: %s", e.Type)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package pocscan
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Target 扫描目标，可为 URL(来自目录扫描或 Web 端口)或 host:port(来自端口扫描)
+type Target struct {
+	Input    string `json:"input"`
+	Scheme   string `json:"scheme"`
+	Host     string `json:"host"`
+	Port     string `json:"port"` // 输入未指定端口时为空
+	Path     string `json:"path"`
+	Hostname string `json:"hostname"` // host:port，未指定端口时同 Host
+}
+
+// ParseTarget 解析目标。host:port 形式的 HTTP 地址按端口推断协议，443/8443 使用 https
+func ParseTarget(input string) (Target, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return Target{}, fmt.Errorf("目标为空")
+	}
+
+	target := Target{Input: input}
+	if strings.Contains(input, "://") {
+		u, err := url.Parse(input)
+		if err != nil || u.Host == "" {
+			return Target{}, fmt.Errorf("无效的 URL: %s", input)
+		}
+		target.Scheme = strings.ToLower(u.Scheme)
+		target.Host = u.Hostname()
+		target.Port = u.Port()
+		target.Path = strings.TrimRight(u.EscapedPath(), "/")
+	} else {
+		host, port, err := net.SplitHostPort(input)
+		if err != nil {
+			host, port = strings.Trim(input, "[]"), ""
+		}
+		target.Host, target.Port = host, port
+		target.Scheme = "http"
+		if port == "443" || port == "8443" {
+			target.Scheme = "https"
+		}
+	}
+
+	target.Hostname = target.Host
+	if strings.Contains(target.Host, ":") {
+		target.Hostname = "[" + target.Host + "]"
+	}
+	if target.Port != "" {
+		target.Hostname = net.JoinHostPort(target.Host, target.Port)
+	}
+	return target, nil
+}
+
+// RootURL 返回 scheme://host[:port]
+func (t Target) RootURL() string {
+	return t.Scheme + "://" + t.Hostname
+}
+
+// BaseURL 返回包含路径的目标地址，不以 / 结尾
+func (t Target) BaseURL() string {
+	return t.RootURL() + t.Path
+}
+
+// variables 返回目标相关的内置变量
+func (t Target) variables() map[string]string {
+	port := t.Port
+	if port == "" {
+		port = "80"
+		if t.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return map[string]string{
+		"BaseURL":  t.BaseURL(),
+		"RootURL":  t.RootURL(),
+		"Hostname": t.Hostname,
+		"Host":     t.Host,
+		"Port":     port,
+		"Path":     t.Path,
+		"Scheme":   t.Scheme,
+		"randstr":  randomString(),
+	}
+}
+
+var variableRegexp = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
+
+// expandVariables 替换 {{name}} 与 {{func(arg)}}，无法求值的表达式保持原样
+func expandVariables(s string, vars map[string]string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	return variableRegexp.ReplaceAllStringFunc(s, func(expr string) string {
+		inner := variableRegexp.FindStringSubmatch(expr)[1]
+		if value, ok := evaluate(inner, vars); ok {
+			return value
+		}
+		return expr
+	})
+}
+
+// 模板表达式支持的辅助函数
+var helperFunctions = map[string]func(string) string{
+	"base64": func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"base64_decode": func(s string) string {
+		data, _ := base64.StdEncoding.DecodeString(s)
+		return string(data)
+	},
+	"url_encode": url.QueryEscape,
+	"url_decode": func(s string) string {
+		decoded, _ := url.QueryUnescape(s)
+		return decoded
+	},
+	"hex_encode": func(s string) string { return hex.EncodeToString([]byte(s)) },
+	"md5": func(s string) string {
+		sum := md5.Sum([]byte(s))
+		return hex.EncodeToString(sum[:])
+	},
+	"to_lower": strings.ToLower,
+	"to_upper": strings.ToUpper,
+}
+
+// evaluate 求值变量名、带引号的字面量或单参数函数调用
+func evaluate(expr string, vars map[string]string) (string, bool) {
+	expr = strings.TrimSpace(expr)
+	if value, ok := vars[expr]; ok {
+		return value, true
+	}
+	if len(expr) >= 2 && (expr[0] == '"' || expr[0] == '\'') && expr[len(expr)-1] == expr[0] {
+		return expr[1 : len(expr)-1], true
+	}
+
+	open := strings.IndexByte(expr, '(')
+	if open <= 0 || !strings.HasSuffix(expr, ")") {
+		return "", false
+	}
+	fn, ok := helperFunctions[strings.TrimSpace(expr[:open])]
+	if !ok {
+		return "", false
+	}
+	arg, ok := evaluate(expr[open+1:len(expr)-1], vars)
+	if !ok {
+		return "", false
+	}
+	return fn(arg), true
+}
+
+func randomString() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
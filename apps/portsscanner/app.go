@@ -3,7 +3,10 @@ package portsscanner
 import (
 	"context"
 	"fmt"
-	"sync/atomic"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -23,168 +26,708 @@ func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
 }
 
-func (a *App) ScanPorts(IP string, startPort int, endPort int, maxThreads int) error {
+// OpenTargetFileDialog 打开目标文件选择对话框
+func (a *App) OpenTargetFileDialog() (string, error) {
+	options := runtime.OpenDialogOptions{
+		Title: "选择目标文件",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "文本文件 (*.txt)",
+				Pattern:     "*.txt",
+			},
+		},
+	}
+
+	return runtime.OpenFileDialog(a.ctx, options)
+}
+
+// OpenWordListDialog 打开用户名/口令字典选择对话框
+func (a *App) OpenWordListDialog() (string, error) {
+	options := runtime.OpenDialogOptions{
+		Title: "选择字典文件",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "文本文件 (*.txt)",
+				Pattern:     "*.txt",
+			},
+		},
+	}
+
+	return runtime.OpenFileDialog(a.ctx, options)
+}
+
+// OpenExcludeFileDialog 打开排除主机文件选择对话框
+func (a *App) OpenExcludeFileDialog() (string, error) {
+	options := runtime.OpenDialogOptions{
+		Title: "选择排除主机文件",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "文本文件 (*.txt)",
+				Pattern:     "*.txt",
+			},
+		},
+	}
+
+	return runtime.OpenFileDialog(a.ctx, options)
+}
+
+// OpenImportFileDialog 选择要导入的 nmap / masscan 扫描结果
+func (a *App) OpenImportFileDialog() (string, error) {
+	options := runtime.OpenDialogOptions{
+		Title: "选择 nmap / masscan 扫描结果",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "扫描结果 (*.xml;*.json;*.gnmap;*.txt)",
+				Pattern:     "*.xml;*.json;*.gnmap;*.txt",
+			},
+			{
+				DisplayName: "所有文件 (*.*)",
+				Pattern:     "*.*",
+			},
+		},
+	}
+
+	return runtime.OpenFileDialog(a.ctx, options)
+}
+
+// ImportCVEFeeds 选择 NVD JSON 数据源(.json / .json.gz)导入本地 CVE 库
+func (a *App) ImportCVEFeeds() (*CVEDatabaseInfo, error) {
+	paths, err := runtime.OpenMultipleFilesDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "选择 NVD JSON 数据源",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "NVD JSON (*.json;*.json.gz)",
+				Pattern:     "*.json;*.json.gz",
+			},
+		},
+	})
+	if err != nil || len(paths) == 0 {
+		return nil, err
+	}
+
+	info, err := ImportCVEFeeds(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// GetCVEDatabaseInfo 返回本地 CVE 库概况
+func (a *App) GetCVEDatabaseInfo() (CVEDatabaseInfo, error) {
+	db, err := loadSharedCVEDatabase()
+	if err != nil {
+		return CVEDatabaseInfo{}, err
+	}
+	info := db.Info()
+	info.Path, _ = DefaultCVEDatabasePath()
+	return info, nil
+}
+
+// OpenProbeFileDialog 选择 nmap-service-probes 格式或 YAML 格式的自定义探针文件
+func (a *App) OpenProbeFileDialog() (string, error) {
+	options := runtime.OpenDialogOptions{
+		Title: "选择自定义探针文件",
+		Filters: []runtime.FileFilter{
+			{
+				DisplayName: "探针文件 (*.txt;*.probes;*.yaml;*.yml)",
+				Pattern:     "*.txt;*.probes;*.yaml;*.yml",
+			},
+			{
+				DisplayName: "所有文件 (*.*)",
+				Pattern:     "*.*",
+			},
+		},
+	}
+
+	return runtime.OpenFileDialog(a.ctx, options)
+}
+
+// ValidateProbeFile 校验探针文件，返回其中的探针、规则数量与逐行的解析错误
+func (a *App) ValidateProbeFile(path string) (ProbeFileInfo, error) {
+	return ValidateProbeFile(path)
+}
+
+// AddProbeFile 加载自定义探针文件，文件有解析错误时拒绝加载
+func (a *App) AddProbeFile(path string) (ProbeFileInfo, error) {
+	return AddProbeFile(path)
+}
+
+// ListProbeFiles 列出已加载的自定义探针文件
+func (a *App) ListProbeFiles() ([]ProbeFileInfo, error) {
+	return ListProbeFiles()
+}
+
+// RemoveProbeFile 移除自定义探针文件
+func (a *App) RemoveProbeFile(name string) error {
+	return RemoveProbeFile(name)
+}
+
+// ScanPorts 启动端口扫描，Target 支持 IP、CIDR、范围、逗号列表和域名，TargetFile 为可选的目标文件，
+// Ports 为 nmap 风格的端口表达式(如 "22,80,443,8000-8100"、"top1000"、"1-65535,!135-139")。
+// 返回扫描 ID，多个扫描可以同时进行，扫描事件的第一个参数均为扫描 ID
+func (a *App) ScanPorts(options ScanOptions) (string, error) {
+	if a == nil || a.ctx == nil {
+		return "", fmt.Errorf("app context is not initialized")
+	}
+
+	config := newScanConfig(options)
+
+	var (
+		targets  []Target
+		ports    []int
+		imported []ImportedPort
+		err      error
+	)
+	// 排除的主机与端口在主机发现之前去掉，不会被探测
+	if config.Excludes, err = config.ResolveExcludes(context.Background()); err != nil {
+		return "", err
+	}
+
+	var excluded int
+	if options.ImportFile != "" {
+		if imported, _, err = LoadScanOutput(options.ImportFile); err != nil {
+			return "", err
+		}
+		imported, excluded = config.Excludes.FilterImported(imported)
+		if len(imported) == 0 {
+			return "", fmt.Errorf("导入的端口全部在排除范围内")
+		}
+		targets, ports = importedScope(imported)
+		options.Ports = portRangeString(ports)
+		options.SkipDiscovery = true
+		config.ScanType = importedScanType(imported)
+	} else {
+		if targets, err = config.ResolveTargets(context.Background()); err != nil {
+			return "", err
+		}
+		if ports, err = config.ResolvePorts(); err != nil {
+			return "", err
+		}
+		targets, ports, excluded = config.Excludes.Filter(targets, ports)
+		if len(targets) == 0 || len(ports) == 0 {
+			return "", fmt.Errorf("目标或端口全部在排除范围内")
+		}
+	}
+	config.Targets = targets
+	config.Ports = ports
+
+	id := newScanID()
+	if err := a.startScan(id, options, config, imported, excluded, nil); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// ResumeScan 从断点继续被停止或异常中断的扫描，已完成的端口不再探测，断点中已发现的端口重新推送
+func (a *App) ResumeScan(id string) error {
 	if a == nil || a.ctx == nil {
 		return fmt.Errorf("app context is not initialized")
 	}
 
+	scanMutex.Lock()
+	_, running := scans[id]
+	scanMutex.Unlock()
+	if running {
+		return fmt.Errorf("扫描 %s 正在进行中", id)
+	}
+
+	cp, err := LoadScanCheckpoint(id)
+	if err != nil {
+		return err
+	}
+
+	config := newScanConfig(cp.Options)
+	config.ScanType = cp.ScanType
+	config.Targets = cp.Targets
+	config.Ports = cp.Ports
+	// 断点中的目标与端口已去掉排除项，不再重新解析，避免域名解析结果变化导致位图错位
+	config.Excludes = &ExcludeList{}
+	return a.startScan(cp.ID, cp.Options, config, cp.Imported, 0, cp)
+}
+
+// ListScanCheckpoints 列出可以继续的扫描
+func (a *App) ListScanCheckpoints() ([]CheckpointSummary, error) {
+	return ListScanCheckpoints()
+}
+
+// DeleteScanCheckpoint 放弃一次未完成的扫描
+func (a *App) DeleteScanCheckpoint(id string) error {
+	return DeleteScanCheckpoint(id)
+}
+
+func newScanConfig(options ScanOptions) ScanConfig {
+	return ScanConfig{
+		ScanType:   options.ScanType,
+		Target:     options.Target,
+		TargetFile: options.TargetFile,
+		IPPrefer:   options.IPPrefer,
+		PortSpec:   options.Ports,
+		MaxThreads: options.MaxThreads,
+		Timeout:    time.Second * 2,
+		MinTimeout: time.Duration(options.MinTimeout) * time.Millisecond,
+		MaxTimeout: time.Duration(options.MaxTimeout) * time.Millisecond,
+		MaxRetries: options.MaxRetries,
+
+		ExcludeHosts: options.ExcludeHosts,
+		ExcludeFile:  options.ExcludeFile,
+		ExcludePorts: options.ExcludePorts,
+
+		ReportClosed:   options.ReportClosed,
+		ReportFiltered: options.ReportFiltered,
+		CheckUnauth:    options.CheckUnauth,
+	}
+}
+
+// startScan 在后台执行扫描，excluded 为已去掉的排除主机:端口组合数，cp 不为空时跳过主机发现并从断点继续。
+// 扫描过程中定期保存断点，停止或出错时保留断点，完成后删除断点并保存历史记录
+func (a *App) startScan(id string, options ScanOptions, config ScanConfig, imported []ImportedPort, excluded int, cp *ScanCheckpoint) error {
+	var err error
+	if options.MatchCVE {
+		if config.CVEDB, err = loadSharedCVEDatabase(); err != nil {
+			return err
+		}
+	}
+
+	var credentials CredentialConfig
+	if options.AuditCredentials {
+		credentials = CredentialConfig{
+			Threads: options.CredentialThreads,
+			Delay:   time.Duration(options.CredentialDelay) * time.Millisecond,
+			Timeout: config.Timeout * 2,
+		}
+		if options.UserFile != "" {
+			if credentials.Users, err = LoadWordList(options.UserFile); err != nil {
+				return err
+			}
+		}
+		if options.PassFile != "" {
+			if credentials.Passwords, err = LoadWordList(options.PassFile); err != nil {
+				return err
+			}
+		}
+	}
+
+	targets, ports := config.Targets, config.Ports
+	hosts := make([]string, 0, len(targets))
+	for _, target := range targets {
+		hosts = append(hosts, target.Label())
+	}
+	startedAt := time.Now()
+	if cp != nil {
+		hosts, startedAt = cp.Hosts, cp.StartedAt
+	}
+
 	scanMutex.Lock()
 	defer scanMutex.Unlock()
 
+	// 从断点继续时，检查之后可能已有同一 ID 的扫描启动
+	if _, ok := scans[id]; ok {
+		return fmt.Errorf("扫描 %s 正在进行中", id)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
-	totalPorts := int32(endPort - startPort + 1)
 
-	// 创建新的 scanControl
+	// 登记新的扫描，与其他扫描互不影响
 	newScan := &scanControl{
-		cancel:     cancel,
-		totalPorts: totalPorts,
-		scanned:    0,
+		id:     id,
+		cancel: cancel,
+		status: "running",
+		progress: ScanProgress{
+			TotalPorts: int32(scanTotal(targets, ports, imported)),
+			Status:     "scanning",
+		},
 	}
+	scans[id] = newScan
 
-	// 原子性地替换 currentScan
-	currentScan = newScan
+	// emitStatus 记录并推送扫描状态
+	emitStatus := func(status string) {
+		scanMutex.Lock()
+		newScan.status = status
+		scanMutex.Unlock()
+		a.emitScan(id, "scan-status", status)
+	}
 
-	config := ScanConfig{
-		Target:     IP,
-		StartPort:  startPort,
-		EndPort:    endPort,
-		MaxThreads: maxThreads,
-		Timeout:    time.Second * 2,
+	// emitProgress 记录并推送进度
+	emitProgress := func(progress ScanProgress) {
+		scanMutex.Lock()
+		newScan.progress = progress
+		scanMutex.Unlock()
+		a.emitScan(id, "scan-progress", progress)
+	}
+	config.Progress = emitProgress
+	config.Warning = func(message string) {
+		a.emitScan(id, "scan-warning", message)
 	}
 
 	go func() {
 		defer func() {
 			if r := recover(); r != nil {
-				runtime.EventsEmit(a.ctx, "scan-error", "Internal error occurred")
+				a.emitScan(id, "scan-error", "Internal error occurred")
 			}
 			scanMutex.Lock()
-			currentScan = nil
+			delete(scans, id)
 			scanMutex.Unlock()
-			runtime.EventsEmit(a.ctx, "scan-status", "idle")
+			a.emitScan(id, "scan-status", "idle")
 		}()
 
 		// 发送初始状态
-		runtime.EventsEmit(a.ctx, "scan-status", "running")
-		runtime.EventsEmit(a.ctx, "scan-progress", map[string]interface{}{
-			"current_port": startPort,
-			"total_ports":  totalPorts,
-			"status":       "scanning",
-		})
-
-		err := ScanPortsCombined(ctx, config, func(portInfo PortInfo) {
-			scanMutex.Lock()
-			if currentScan == nil {
-				scanMutex.Unlock()
-				return
-			}
-			scanMutex.Unlock()
+		emitStatus("running")
+		emitProgress(newScan.progress)
 
-			if portInfo.Protocol == "progress" {
-				scanned := atomic.AddInt32(&currentScan.scanned, 1)
-				runtime.EventsEmit(a.ctx, "scan-progress", map[string]interface{}{
-					"current_port": portInfo.Port,
-					"total_ports":  totalPorts,
-					"scanned":      scanned,
-					"status":       "scanning",
-				})
+		var err error
+		if cp == nil && (!options.SkipDiscovery || hasLinkTargets(targets)) {
+			emitStatus("discovering")
+			if options.SkipDiscovery {
+				// 跳过主机发现时仍需在链路上展开 IPv6 网段，断点按展开后的目标记录
+				config.Targets, err = ExpandLinkTargets(ctx, targets)
 			} else {
-				// 发送完整的端口信息，包括指纹识别结果
-				runtime.EventsEmit(a.ctx, "port-found", map[string]interface{}{
-					"port":             portInfo.Port,
-					"protocol":         portInfo.Protocol,
-					"service":          portInfo.Service,
-					"product_name":     portInfo.ProductName,
-					"version":          portInfo.Version,
-					"info":             portInfo.Info,
-					"hostname":         portInfo.Hostname,
-					"operating_system": portInfo.OperatingSystem,
-					"device_type":      portInfo.DeviceType,
-					"probe_name":       portInfo.ProbeName,
-					"tls":              portInfo.TLS,
+				// 主机发现，只对存活主机进行端口扫描
+				config.Targets, err = DiscoverHosts(ctx, targets, config.MaxThreads, config.Timeout, func(host HostInfo) {
+					a.emitScan(id, "host-alive", host)
 				})
 			}
-		})
 
-		scanMutex.Lock()
-		defer scanMutex.Unlock()
+			if err == nil {
+				emitStatus("running")
+				emitProgress(ScanProgress{
+					TotalPorts: int32(len(config.Targets) * len(ports)),
+					Status:     "scanning",
+				})
+			}
+		}
+
+		var (
+			stats     = &ScanStats{}
+			openPorts []PortInfo
+			portsMu   sync.Mutex
+		)
+		onPort := func(portInfo PortInfo) {
+			if portInfo.State == StateOpen {
+				portsMu.Lock()
+				openPorts = append(openPorts, portInfo)
+				portsMu.Unlock()
+			}
 
-		if currentScan == nil {
-			return
+			// 发送完整的端口信息，包括指纹识别结果
+			a.emitScan(id, "port-found", map[string]interface{}{
+				"host":             portInfo.Host,
+				"port":             portInfo.Port,
+				"protocol":         portInfo.Protocol,
+				"state":            portInfo.State,
+				"service":          portInfo.Service,
+				"product_name":     portInfo.ProductName,
+				"version":          portInfo.Version,
+				"info":             portInfo.Info,
+				"hostname":         portInfo.Hostname,
+				"operating_system": portInfo.OperatingSystem,
+				"device_type":      portInfo.DeviceType,
+				"probe_name":       portInfo.ProbeName,
+				"tls":              portInfo.TLS,
+				"banner":           portInfo.Banner,
+				"certificate":      portInfo.Certificate,
+				"http":             portInfo.HTTP,
+				"details":          portInfo.Details,
+				"cpe":              portInfo.CPE,
+				"cves":             portInfo.CVEs,
+				"findings":         portInfo.Findings,
+			})
+		}
+
+		var hostInfos []HostOSInfo
+		config.HostInfo = func(info HostOSInfo) {
+			hostInfos = append(hostInfos, info)
+			a.emitScan(id, "host-info", info)
 		}
 
+		if cp != nil {
+			// 重新推送断点中已发现的端口
+			for _, portInfo := range cp.results() {
+				onPort(portInfo)
+			}
+		} else if err == nil {
+			cp = newScanCheckpoint(id, options, config, hosts, imported)
+			cp.Stats.Excluded = int32(excluded)
+		}
+		config.Checkpoint = cp
+
+		// 定期保存断点，程序异常退出时最多丢失一个间隔内的进度
+		saveDone := make(chan struct{})
+		var saveWg sync.WaitGroup
+		if cp != nil {
+			saveWg.Add(1)
+			go func() {
+				defer saveWg.Done()
+				ticker := time.NewTicker(checkpointInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ticker.C:
+						cp.Save()
+					case <-saveDone:
+						return
+					}
+				}
+			}()
+		}
+
+		switch {
+		case err != nil:
+		case imported != nil:
+			stats, err = FingerprintPorts(ctx, config, imported, onPort)
+		case len(config.Targets) > 0:
+			stats, err = ScanPortsCombined(ctx, config, onPort)
+		}
+		close(saveDone)
+		saveWg.Wait()
+
+		if err == nil && options.AuditCredentials && len(openPorts) > 0 {
+			emitStatus("auditing")
+			err = AuditCredentials(ctx, openPorts, credentials, func(cred WeakCredential) {
+				a.emitScan(id, "weak-credential", cred)
+			})
+		}
+
+		// 停止或出错时保留断点以便继续，完成后删除
+		if err != nil && cp != nil {
+			if saveErr := cp.Save(); saveErr != nil {
+				a.emitScan(id, "scan-error", fmt.Sprintf("扫描断点未保存，无法继续本次扫描: %v", saveErr))
+			} else {
+				a.emitScan(id, "scan-checkpoint", id)
+			}
+		} else if err == nil {
+			DeleteScanCheckpoint(id)
+		}
+
+		scanMutex.Lock()
+		progress := newScan.progress
+		scanMutex.Unlock()
+
 		if err != nil {
+			progress.Status = "cancelled"
 			if err == context.Canceled {
-				runtime.EventsEmit(a.ctx, "scan-status", "cancelled")
-				runtime.EventsEmit(a.ctx, "scan-progress", map[string]interface{}{
-					"current_port": atomic.LoadInt32(&currentScan.scanned),
-					"total_ports":  totalPorts,
-					"status":       "cancelled",
-				})
+				emitStatus("cancelled")
 			} else {
-				runtime.EventsEmit(a.ctx, "scan-error", err.Error())
-				runtime.EventsEmit(a.ctx, "scan-status", "error")
-				runtime.EventsEmit(a.ctx, "scan-progress", map[string]interface{}{
-					"current_port": atomic.LoadInt32(&currentScan.scanned),
-					"total_ports":  totalPorts,
-					"status":       "error",
-				})
+				progress.Status = "error"
+				a.emitScan(id, "scan-error", err.Error())
+				emitStatus("error")
 			}
+			a.emitScan(id, "scan-progress", progress)
 		} else {
-			runtime.EventsEmit(a.ctx, "scan-complete", map[string]interface{}{
-				"total_ports": totalPorts,
-				"scanned":     atomic.LoadInt32(&currentScan.scanned),
-			})
-			runtime.EventsEmit(a.ctx, "scan-status", "completed")
-			runtime.EventsEmit(a.ctx, "scan-progress", map[string]interface{}{
-				"current_port": endPort,
-				"total_ports":  totalPorts,
-				"status":       "completed",
+			progress.Status = "completed"
+
+			// 保存结果，用于与同一目标的后续扫描对比
+			record := &ScanRecord{
+				ScanSummary: ScanSummary{
+					ID:         newScan.id,
+					Target:     historyTarget(options),
+					PortSpec:   options.Ports,
+					ScanType:   config.ScanType,
+					StartedAt:  startedAt,
+					FinishedAt: time.Now(),
+				},
+				Targets:  hosts,
+				Stats:    *stats,
+				Ports:    openPorts,
+				HostInfo: hostInfos,
+			}
+			recordID := record.ID
+			if err := SaveScanRecord(record); err != nil {
+				recordID = ""
+				a.emitScan(id, "scan-error", fmt.Sprintf("扫描结果未保存到历史记录: %v", err))
+			}
+
+			a.emitScan(id, "scan-complete", map[string]interface{}{
+				"total_ports": progress.TotalPorts,
+				"scanned":     progress.Scanned,
+				"states":      stats,
+				"record_id":   recordID,
 			})
+			emitStatus("completed")
+			a.emitScan(id, "scan-progress", progress)
 		}
 	}()
 
 	return nil
 }
 
-func (a *App) StopScan() error {
+// historyTarget 返回用于归类扫描历史的目标，同一目标的扫描结果可以相互对比
+func historyTarget(options ScanOptions) string {
+	if options.ImportFile != "" {
+		return "import:" + filepath.Base(options.ImportFile)
+	}
+	target := strings.TrimSpace(options.Target)
+	if options.TargetFile != "" {
+		if target != "" {
+			target += ","
+		}
+		target += "file:" + filepath.Base(options.TargetFile)
+	}
+	return target
+}
+
+// ListScanHistory 列出扫描历史，target 不为空时只返回该目标的记录
+func (a *App) ListScanHistory(target string) ([]ScanSummary, error) {
+	return ListScanHistory(target)
+}
+
+// GetScanRecord 读取一次扫描的完整结果
+func (a *App) GetScanRecord(id string) (*ScanRecord, error) {
+	return LoadScanRecord(id)
+}
+
+// DeleteScanRecord 删除一次扫描的历史记录
+func (a *App) DeleteScanRecord(id string) error {
+	return DeleteScanRecord(id)
+}
+
+// DiffScans 对比两次扫描，返回新增、消失与服务变化的端口
+func (a *App) DiffScans(baseID, compareID string) (*ScanDiff, error) {
+	return DiffScanRecords(baseID, compareID)
+}
+
+// ExportScanDiff 对比两次扫描并导出为 CSV 或 JSON，返回保存的路径，用户取消时为空
+func (a *App) ExportScanDiff(baseID, compareID string) (string, error) {
+	diff, err := DiffScanRecords(baseID, compareID)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出对比结果",
+		DefaultFilename: fmt.Sprintf("diff_%s_%s.csv", diff.Base.ID, diff.Compare.ID),
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV (*.csv)", Pattern: "*.csv"},
+			{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, ExportScanDiff(path, diff)
+}
+
+// ExportResults 导出当前的端口扫描结果，options 为本次扫描参数，用于报告中的目标与端口范围
+func (a *App) ExportResults(ports []PortInfo, options ScanOptions) (string, error) {
+	if len(ports) == 0 {
+		return "", fmt.Errorf("没有可导出的扫描结果")
+	}
+	record := &ScanRecord{
+		ScanSummary: ScanSummary{
+			Target:     historyTarget(options),
+			PortSpec:   options.Ports,
+			ScanType:   options.ScanType,
+			FinishedAt: time.Now(),
+		},
+		Ports: ports,
+	}
+	if options.ImportFile != "" {
+		// 导入结果没有端口表达式，按实际端口生成
+		var portList []int
+		for _, p := range ports {
+			portList = append(portList, p.Port)
+		}
+		record.PortSpec = portRangeString(dedupPorts(portList))
+	}
+	return a.exportRecord(record, "scan_"+time.Now().Format("20060102-150405"))
+}
+
+// ExportScanRecord 导出一次历史扫描的结果
+func (a *App) ExportScanRecord(id string) (string, error) {
+	record, err := LoadScanRecord(id)
+	if err != nil {
+		return "", err
+	}
+	return a.exportRecord(record, "scan_"+record.ID)
+}
+
+// exportRecord 弹出保存对话框并按所选扩展名导出，用户取消时返回空路径
+func (a *App) exportRecord(record *ScanRecord, name string) (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出扫描结果",
+		DefaultFilename: name + ".html",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "HTML 报告 (*.html)", Pattern: "*.html"},
+			{DisplayName: "nmap XML (*.xml)", Pattern: "*.xml"},
+			{DisplayName: "JSON (*.json)", Pattern: "*.json"},
+			{DisplayName: "CSV (*.csv)", Pattern: "*.csv"},
+		},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+	return path, ExportScanRecord(path, record)
+}
+
+// CanSYNScan 检查当前进程是否具备 SYN 扫描所需的原始套接字权限
+func (a *App) CanSYNScan() bool {
+	syn, err := newSYNScanner()
+	if err != nil {
+		return false
+	}
+	syn.Close()
+	return true
+}
+
+// StopScan 停止指定的扫描，已完成的进度保存在断点中
+func (a *App) StopScan(id string) error {
 	scanMutex.Lock()
 	defer scanMutex.Unlock()
 
-	if currentScan != nil && currentScan.cancel != nil {
-		currentScan.cancel()
-		runtime.EventsEmit(a.ctx, "scan-status", "stopping")
-		runtime.EventsEmit(a.ctx, "scan-progress", map[string]interface{}{
-			"current_port": atomic.LoadInt32(&currentScan.scanned),
-			"total_ports":  currentScan.totalPorts,
-			"status":       "stopping",
-		})
+	scan, ok := scans[id]
+	if !ok {
+		return fmt.Errorf("扫描 %s 不存在或已结束", id)
 	}
+	scan.cancel()
+	scan.status = "stopping"
+	a.emitScan(id, "scan-status", "stopping")
+	progress := scan.progress
+	progress.Status = "stopping"
+	a.emitScan(id, "scan-progress", progress)
 	return nil
 }
 
-func (a *App) GetScanStatus() string {
+// GetScanStatus 返回指定扫描的当前状态，扫描不存在或已结束时返回 idle
+func (a *App) GetScanStatus(id string) string {
 	scanMutex.Lock()
 	defer scanMutex.Unlock()
 
-	if currentScan != nil {
-		return "running"
+	if scan, ok := scans[id]; ok {
+		return scan.status
 	}
 	return "idle"
 }
 
-func (a *App) GetScanProgress() ScanProgress {
+// GetScanProgress 返回指定扫描最近一次上报的进度
+func (a *App) GetScanProgress(id string) ScanProgress {
 	scanMutex.Lock()
 	defer scanMutex.Unlock()
 
-	if currentScan == nil {
+	scan, ok := scans[id]
+	if !ok {
 		return ScanProgress{
 			Status: "idle",
 		}
 	}
 
-	return ScanProgress{
-		CurrentPort: atomic.LoadInt32(&currentScan.scanned),
-		TotalPorts:  currentScan.totalPorts,
-		Status:      "running",
+	progress := scan.progress
+	progress.Status = scan.status
+	return progress
+}
+
+// ListScans 返回正在进行的扫描 ID
+func (a *App) ListScans() []string {
+	scanMutex.Lock()
+	defer scanMutex.Unlock()
+
+	ids := make([]string, 0, len(scans))
+	for id := range scans {
+		ids = append(ids, id)
 	}
+	sort.Strings(ids)
+	return ids
+}
+
+// emitScan 推送扫描事件，第一个参数为扫描 ID，前端据此区分同时进行的扫描
+func (a *App) emitScan(id, name string, data interface{}) {
+	runtime.EventsEmit(a.ctx, name, id, data)
 }
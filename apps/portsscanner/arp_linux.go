@@ -0,0 +1,104 @@
+//go:build linux
+
+package portsscanner
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// 内核邻居表项状态(NUD_*)与属性类型(NDA_*)
+const (
+	nudIncomplete = 0x01
+	nudFailed     = 0x20
+	nudNoARP      = 0x40 // 组播等无需解析的表项
+	ndaDst        = 1
+	ndaLLAddr     = 2
+	ndMsgLen      = 12 // struct ndmsg
+)
+
+// neighborLookup 从内核邻居表中查找已完成解析的 MAC 地址，IPv4 读取 ARP 表，IPv6 读取 NDP 邻居表
+func neighborLookup(ip net.IP) string {
+	if ip.To4() == nil {
+		return ipv6Neighbors()[ip.String()]
+	}
+	return arpLookup(ip)
+}
+
+// arpLookup 从内核 ARP 表中查找已完成解析的 MAC 地址
+func arpLookup(ip net.IP) string {
+	file, err := os.Open("/proc/net/arp")
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	target := ip.String()
+	scanner := bufio.NewScanner(file)
+	scanner.Scan() // 跳过表头
+	for scanner.Scan() {
+		// IP address  HW type  Flags  HW address  Mask  Device
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] != target {
+			continue
+		}
+		if fields[2] == "0x0" || fields[3] == "00:00:00:00:00:00" {
+			return ""
+		}
+		return fields[3]
+	}
+	return ""
+}
+
+// ipv6Neighbors 通过 netlink 读取 IPv6 邻居表，返回 地址 -> MAC，忽略未完成、失败与组播表项
+func ipv6Neighbors() map[string]string {
+	data, err := syscall.NetlinkRIB(syscall.RTM_GETNEIGH, syscall.AF_INET6)
+	if err != nil {
+		return nil
+	}
+	msgs, err := syscall.ParseNetlinkMessage(data)
+	if err != nil {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWNEIGH || len(m.Data) < ndMsgLen {
+			continue
+		}
+		state := binary.NativeEndian.Uint16(m.Data[8:10])
+		if state&(nudIncomplete|nudFailed|nudNoARP) != 0 {
+			continue
+		}
+
+		var dst net.IP
+		var mac net.HardwareAddr
+		for attrs := m.Data[ndMsgLen:]; len(attrs) >= 4; {
+			length := int(binary.NativeEndian.Uint16(attrs[0:2]))
+			if length < 4 || length > len(attrs) {
+				break
+			}
+			value := attrs[4:length]
+			switch binary.NativeEndian.Uint16(attrs[2:4]) {
+			case ndaDst:
+				dst = net.IP(append([]byte(nil), value...))
+			case ndaLLAddr:
+				mac = net.HardwareAddr(append([]byte(nil), value...))
+			}
+			// 属性按 4 字节对齐
+			next := (length + 3) &^ 3
+			if next > len(attrs) {
+				break
+			}
+			attrs = attrs[next:]
+		}
+		if len(dst) == net.IPv6len && len(mac) > 0 {
+			result[dst.String()] = mac.String()
+		}
+	}
+	return result
+}
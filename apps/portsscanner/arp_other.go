@@ -0,0 +1,15 @@
+//go:build !linux
+
+package portsscanner
+
+import "net"
+
+// neighborLookup 非 Linux 平台暂不读取邻居表
+func neighborLookup(ip net.IP) string {
+	return ""
+}
+
+// ipv6Neighbors 非 Linux 平台暂不读取邻居表
+func ipv6Neighbors() map[string]string {
+	return nil
+}
@@ -0,0 +1,57 @@
+package portsscanner
+
+import (
+	"net"
+	"strconv"
+	"time"
+)
+
+const (
+	// 被动等待 Banner 的最短时间，SSH/FTP/SMTP 等服务通常在连接建立后立即发送
+	minBannerWait = 500 * time.Millisecond
+	// 收到首个数据包后继续等待后续数据的时间
+	bannerReadGap = 100 * time.Millisecond
+	maxBannerSize = 4096
+)
+
+// grabBanner 在已建立的连接上被动读取服务端主动发送的数据，即 nmap 的 NULL 探针
+func grabBanner(conn net.Conn, wait time.Duration) []byte {
+	buf := make([]byte, maxBannerSize)
+	n := 0
+	deadline := time.Now().Add(wait)
+	for n < len(buf) {
+		_ = conn.SetReadDeadline(deadline)
+		m, err := conn.Read(buf[n:])
+		n += m
+		if err != nil {
+			break
+		}
+		deadline = time.Now().Add(bannerReadGap)
+	}
+	return buf[:n]
+}
+
+// matchBanner 使用 NULL 探针的规则匹配 Banner，仅在命中带版本信息的 match 时返回 true
+func matchBanner(portInfo *PortInfo, banner []byte) bool {
+	probe, ok := tcpProbeDB().byName["TCP_NULL"]
+	if !ok || len(banner) == 0 {
+		return false
+	}
+
+	fp, hard := probe.find(bytesToLatin1(banner))
+	if !hard {
+		return false
+	}
+	fp.ProbeName = probe.name
+	fillFingerPrint(portInfo, fp)
+	return true
+}
+
+// escapeBanner 将原始 Banner 转义为可显示的字符串，超出 maxBannerSize 的部分截断
+func escapeBanner(banner []byte) string {
+	if len(banner) > maxBannerSize {
+		banner = banner[:maxBannerSize]
+	}
+	quoted := strconv.Quote(string(banner))
+	return quoted[1 : len(quoted)-1]
+}
@@ -0,0 +1,247 @@
+package portsscanner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 扫描过程中保存断点的间隔
+const checkpointInterval = 5 * time.Second
+
+// ScanCheckpoint 扫描断点，记录扫描参数、已完成的主机/端口位图与已上报的结果，
+// 扫描被停止或程序异常退出后可以从断点继续
+type ScanCheckpoint struct {
+	ID        string         `json:"id"`
+	Options   ScanOptions    `json:"options"`
+	ScanType  string         `json:"scan_type"` // 实际使用的扫描方式
+	Hosts     []string       `json:"hosts"`     // 主机发现前的全部目标，用于历史记录
+	Targets   []Target       `json:"targets"`   // 主机发现后待扫描的目标
+	Ports     []int          `json:"ports"`
+	Imported  []ImportedPort `json:"imported,omitempty"`
+	Done      []byte         `json:"done"` // 已完成位图，下标为 主机序号*端口数+端口序号，导入时为导入端口的序号
+	Scanned   int            `json:"scanned"`
+	Stats     ScanStats      `json:"stats"`
+	Results   []PortInfo     `json:"results"` // 已上报的端口
+	StartedAt time.Time      `json:"started_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+
+	mu    sync.Mutex
+	dirty bool
+}
+
+// CheckpointSummary 可继续的扫描，用于列表展示
+type CheckpointSummary struct {
+	ID         string    `json:"id"`
+	Target     string    `json:"target"`
+	PortSpec   string    `json:"port_spec"`
+	ScanType   string    `json:"scan_type"`
+	ImportFile string    `json:"import_file,omitempty"` // 导入扫描结果时的文件
+	Total      int       `json:"total"`
+	Scanned    int       `json:"scanned"`
+	OpenPorts  int       `json:"open_ports"`
+	StartedAt  time.Time `json:"started_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+var checkpointMutex sync.Mutex
+
+func checkpointDir() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "checkpoints")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建断点目录失败: %w", err)
+	}
+	return dir, nil
+}
+
+func checkpointPath(id string) (string, error) {
+	if err := validScanID(id); err != nil {
+		return "", err
+	}
+	dir, err := checkpointDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, id+".json.gz"), nil
+}
+
+// newScanCheckpoint 创建新的扫描断点，config.Targets 为主机发现后的目标
+func newScanCheckpoint(id string, options ScanOptions, config ScanConfig, hosts []string, imported []ImportedPort) *ScanCheckpoint {
+	cp := &ScanCheckpoint{
+		ID:        id,
+		Options:   options,
+		ScanType:  config.ScanType,
+		Hosts:     hosts,
+		Targets:   config.Targets,
+		Ports:     config.Ports,
+		Imported:  imported,
+		StartedAt: time.Now(),
+		dirty:     true,
+	}
+	cp.Done = make([]byte, (cp.total()+7)/8)
+	return cp
+}
+
+// total 返回需要探测的端口总数
+func (cp *ScanCheckpoint) total() int {
+	return scanTotal(cp.Targets, cp.Ports, cp.Imported)
+}
+
+// isDone 判断第 i 个主机/端口是否已经扫描
+func (cp *ScanCheckpoint) isDone(i int) bool {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.Done[i/8]&(1<<(i%8)) != 0
+}
+
+// markDone 记录第 i 个主机/端口的扫描结果，reported 表示该端口已上报
+func (cp *ScanCheckpoint) markDone(i int, portInfo PortInfo, reported bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if cp.Done[i/8]&(1<<(i%8)) != 0 {
+		return
+	}
+	cp.Done[i/8] |= 1 << (i % 8)
+	cp.Scanned++
+	cp.Stats.add(portInfo.State)
+	if reported {
+		cp.Results = append(cp.Results, portInfo)
+	}
+	cp.dirty = true
+}
+
+// progress 返回已扫描的端口数与各状态统计，用于恢复进度
+func (cp *ScanCheckpoint) progress() (int, ScanStats) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.Scanned, cp.Stats
+}
+
+// results 返回已上报端口的副本
+func (cp *ScanCheckpoint) results() []PortInfo {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return append([]PortInfo(nil), cp.Results...)
+}
+
+func (cp *ScanCheckpoint) summary() CheckpointSummary {
+	open := 0
+	for _, p := range cp.Results {
+		if p.State == StateOpen {
+			open++
+		}
+	}
+	return CheckpointSummary{
+		ID:         cp.ID,
+		Target:     historyTarget(cp.Options),
+		PortSpec:   cp.Options.Ports,
+		ScanType:   cp.ScanType,
+		ImportFile: cp.Options.ImportFile,
+		Total:      cp.total(),
+		Scanned:    cp.Scanned,
+		OpenPorts:  open,
+		StartedAt:  cp.StartedAt,
+		UpdatedAt:  cp.UpdatedAt,
+	}
+}
+
+// Save 在有新进度时写入断点文件
+func (cp *ScanCheckpoint) Save() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if !cp.dirty {
+		return nil
+	}
+
+	path, err := checkpointPath(cp.ID)
+	if err != nil {
+		return err
+	}
+	cp.UpdatedAt = time.Now()
+
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	if err := writeJSONGzip(path, cp); err != nil {
+		return fmt.Errorf("保存扫描断点失败: %w", err)
+	}
+	cp.dirty = false
+	return nil
+}
+
+// LoadScanCheckpoint 读取指定扫描的断点
+func LoadScanCheckpoint(id string) (*ScanCheckpoint, error) {
+	path, err := checkpointPath(id)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+
+	var cp ScanCheckpoint
+	err = readJSONGzip(path, &cp)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("扫描断点 %s 不存在", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取扫描断点失败: %w", err)
+	}
+	if len(cp.Done) != (cp.total()+7)/8 {
+		return nil, fmt.Errorf("扫描断点 %s 已损坏", id)
+	}
+	return &cp, nil
+}
+
+// ListScanCheckpoints 按时间倒序列出可继续的扫描
+func ListScanCheckpoints() ([]CheckpointSummary, error) {
+	dir, err := checkpointDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CheckpointSummary, 0, len(entries))
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".json.gz")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		cp, err := LoadScanCheckpoint(id)
+		if err != nil {
+			// 跳过损坏的断点，不影响其他断点
+			continue
+		}
+		result = append(result, cp.summary())
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].StartedAt.After(result[j].StartedAt)
+	})
+	return result, nil
+}
+
+// DeleteScanCheckpoint 删除扫描断点
+func DeleteScanCheckpoint(id string) error {
+	path, err := checkpointPath(id)
+	if err != nil {
+		return err
+	}
+
+	checkpointMutex.Lock()
+	defer checkpointMutex.Unlock()
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package portsscanner
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// CPE 归一化后的通用平台枚举，只保留匹配 CVE 所需的字段
+type CPE struct {
+	Part    string // a 应用 / o 操作系统 / h 硬件
+	Vendor  string
+	Product string
+	Version string
+}
+
+// String 返回 CPE 2.3 格式字符串
+func (c CPE) String() string {
+	version := c.Version
+	if version == "" {
+		version = "*"
+	}
+	return "cpe:2.3:" + c.Part + ":" + c.Vendor + ":" + c.Product + ":" + version + ":*:*:*:*:*:*:*"
+}
+
+// URI 返回 nmap 使用的 CPE 2.2 格式，如 "cpe:/a:openbsd:openssh:7.4"
+func (c CPE) URI() string {
+	uri := "cpe:/" + c.Part + ":" + c.Vendor + ":" + c.Product
+	if c.Version != "" {
+		uri += ":" + c.Version
+	}
+	return uri
+}
+
+// key 返回 CVE 库中的索引键 vendor:product
+func (c CPE) key() string {
+	return c.Vendor + ":" + c.Product
+}
+
+// parseCPE 解析 CPE 2.3("cpe:2.3:a:vendor:product:version:...")或 2.2("cpe:/a:vendor:product:version")格式
+func parseCPE(s string) (CPE, bool) {
+	var fields []string
+	switch {
+	case strings.HasPrefix(s, "cpe:2.3:"):
+		fields = splitCPE(s[len("cpe:2.3:"):])
+	case strings.HasPrefix(s, "cpe:/"):
+		fields = strings.Split(s[len("cpe:/"):], ":")
+	default:
+		// nmap 探针中的 cpe 字段已去掉 "cpe:/" 前缀
+		fields = strings.Split(s, ":")
+	}
+	if len(fields) < 3 || fields[0] == "" || fields[1] == "" || fields[2] == "" {
+		return CPE{}, false
+	}
+
+	cpe := CPE{
+		Part:    fields[0],
+		Vendor:  strings.ToLower(fields[1]),
+		Product: strings.ToLower(fields[2]),
+	}
+	if len(fields) > 3 && fields[3] != "*" && fields[3] != "-" {
+		cpe.Version = fields[3]
+	}
+	return cpe, true
+}
+
+// splitCPE 按未转义的冒号切分 CPE 2.3 字段并去掉转义符
+func splitCPE(s string) []string {
+	var fields []string
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s):
+			i++
+			b.WriteByte(s[i])
+		case s[i] == ':':
+			fields = append(fields, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return append(fields, b.String())
+}
+
+// cpeAliases 同一产品在 nmap 与 NVD 中使用的不同厂商名，NVD 历史上也多次更换
+var cpeAliases = map[string][]string{
+	"igor_sysoev:nginx":           {"nginx:nginx", "f5:nginx"},
+	"nginx:nginx":                 {"f5:nginx"},
+	"redislabs:redis":             {"redis:redis"},
+	"mysql:mysql":                 {"oracle:mysql"},
+	"elasticsearch:elasticsearch": {"elastic:elasticsearch"},
+	"microsoft:iis":               {"microsoft:internet_information_services"},
+}
+
+// serverHeaderCPEs Server 响应头中常见产品名与 CPE 的对应关系，其余名称再到探针库中查找
+var serverHeaderCPEs = map[string]CPE{
+	"nginx":         {Part: "a", Vendor: "igor_sysoev", Product: "nginx"},
+	"apache":        {Part: "a", Vendor: "apache", Product: "http_server"},
+	"microsoft-iis": {Part: "a", Vendor: "microsoft", Product: "internet_information_services"},
+	"openresty":     {Part: "a", Vendor: "openresty", Product: "openresty"},
+	"tengine":       {Part: "a", Vendor: "alibaba", Product: "tengine"},
+	"jetty":         {Part: "a", Vendor: "eclipse", Product: "jetty"},
+	"lighttpd":      {Part: "a", Vendor: "lighttpd", Product: "lighttpd"},
+	"caddy":         {Part: "a", Vendor: "caddyserver", Product: "caddy"},
+}
+
+var (
+	productCPEs     map[string]CPE
+	productCPEsOnce sync.Once
+)
+
+// productCPEIndex 从 nmap-service-probes 的 match 规则中提取 "产品名 → CPE" 对应关系，
+// 只收录产品名为固定文本(不含 $1 等捕获组)且带有应用类 CPE 的规则
+func productCPEIndex() map[string]CPE {
+	productCPEsOnce.Do(func() {
+		productCPEs = make(map[string]CPE)
+		for _, db := range []*probeDB{tcpProbeDB(), udpProbeDB()} {
+			for _, probe := range db.probes {
+				for _, m := range probe.matches {
+					product := m.versionInfo["p"]
+					if product == "" || strings.Contains(product, "$") {
+						continue
+					}
+					key := strings.ToLower(product)
+					if _, ok := productCPEs[key]; ok {
+						continue
+					}
+					for _, tmpl := range strings.Fields(m.versionInfo["cpe"]) {
+						if cpe, ok := parseCPE(tmpl); ok && cpe.Part == "a" && !strings.Contains(cpe.Product, "$") {
+							cpe.Version = ""
+							productCPEs[key] = cpe
+							break
+						}
+					}
+				}
+			}
+		}
+	})
+	return productCPEs
+}
+
+var versionRegexp = regexp.MustCompile(`^[vV]?(\d+(?:\.\d+)*(?:[a-zA-Z]+\d*)?)`)
+
+// normalizeVersion 从 gonmap 的版本字符串中取出可比较的版本号，
+// 如 "7.4p1 Debian 10+deb9u7" → "7.4p1"、"8.0.28-0ubuntu0.20.04.3" → "8.0.28"
+func normalizeVersion(version string) string {
+	match := versionRegexp.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// normalizeCPE 根据指纹识别出的产品名与版本生成 CPE，Web 端口未识别出产品时使用 Server 响应头
+func normalizeCPE(portInfo PortInfo) (CPE, bool) {
+	if portInfo.ProductName != "" {
+		if cpe, ok := productCPEIndex()[strings.ToLower(portInfo.ProductName)]; ok {
+			cpe.Version = normalizeVersion(portInfo.Version)
+			return cpe, true
+		}
+	}
+
+	if portInfo.HTTP != nil && strings.TrimSpace(portInfo.HTTP.Server) != "" {
+		// 形如 "nginx/1.18.0 (Ubuntu)"
+		name, version, _ := strings.Cut(strings.Fields(portInfo.HTTP.Server)[0], "/")
+		name = strings.ToLower(name)
+		cpe, ok := serverHeaderCPEs[name]
+		if !ok {
+			cpe, ok = productCPEIndex()[name]
+		}
+		if ok {
+			cpe.Version = normalizeVersion(version)
+			return cpe, true
+		}
+	}
+	return CPE{}, false
+}
+
+// compareVersions 逐段比较版本号，数字段按数值比较，返回 -1、0、1。
+// 字母段视为补丁号(7.4p1 > 7.4)，alpha/beta/rc 等预发布标记除外(1.0rc1 < 1.0)
+func compareVersions(a, b string) int {
+	ta, tb := versionTokens(a), versionTokens(b)
+	for i := 0; i < len(ta) || i < len(tb); i++ {
+		// 缺少的末尾数字段视为 0: 1.0 == 1.0.0
+		switch {
+		case i >= len(ta):
+			if c := tailOrder(tb[i]); c != 0 {
+				return -c
+			}
+			continue
+		case i >= len(tb):
+			if c := tailOrder(ta[i]); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		na, errA := strconv.Atoi(ta[i])
+		nb, errB := strconv.Atoi(tb[i])
+		switch {
+		case errA == nil && errB == nil:
+			if na != nb {
+				return compareInt(na, nb)
+			}
+		case errA == nil:
+			// 数字段大于字母段: 1.0.1 > 1.0rc
+			return 1
+		case errB == nil:
+			return -1
+		default:
+			if c := strings.Compare(strings.ToLower(ta[i]), strings.ToLower(tb[i])); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+// tailOrder 较长版本号多出的段与缺失段比较的结果，数字段与 0 比较，为 0 时继续比较后续段
+func tailOrder(token string) int {
+	if n, err := strconv.Atoi(token); err == nil {
+		if n == 0 {
+			return 0
+		}
+		return 1
+	}
+	switch strings.ToLower(token) {
+	case "alpha", "a", "beta", "b", "rc", "pre", "dev", "snapshot":
+		return -1
+	}
+	return 1
+}
+
+func compareInt(a, b int) int {
+	if a < b {
+		return -1
+	}
+	return 1
+}
+
+// versionTokens 将版本号切分为连续的数字段与字母段，忽略分隔符
+func versionTokens(version string) []string {
+	var tokens []string
+	var b strings.Builder
+	lastDigit := false
+	for _, r := range version {
+		isDigit := unicode.IsDigit(r)
+		if !isDigit && !unicode.IsLetter(r) {
+			if b.Len() > 0 {
+				tokens = append(tokens, b.String())
+				b.Reset()
+			}
+			continue
+		}
+		if b.Len() > 0 && isDigit != lastDigit {
+			tokens = append(tokens, b.String())
+			b.Reset()
+		}
+		b.WriteRune(r)
+		lastDigit = isDigit
+	}
+	if b.Len() > 0 {
+		tokens = append(tokens, b.String())
+	}
+	return tokens
+}
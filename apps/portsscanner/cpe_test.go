@@ -0,0 +1,38 @@
+package portsscanner
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2", "1.2", 0},
+		{"2.4.49", "2.4.50", -1},
+		{"1.10", "1.9", 1},
+		{"1.2.10", "1.2.9", 1},
+		// 缺少的末尾段视为 0
+		{"1.2", "1.2.0", 0},
+		{"1.0", "1.0.0.0", 0},
+		{"1.2.0.1", "1.2", 1},
+		{"1.2", "1.2.0.0.1", -1},
+		{"1.2.0.0", "1.2.1", -1},
+		// 字母段为补丁号，预发布标记小于正式版本
+		{"7.4p1", "7.4", 1},
+		{"7.4p1", "7.4p2", -1},
+		{"1.0rc1", "1.0", -1},
+		{"1.0-beta", "1.0.0", -1},
+		{"1.0.0-rc1", "1.0", -1},
+		{"1.0-alpha", "1.0-beta", -1},
+		{"1.0.1", "1.0rc", 1},
+		{"1.0RC1", "1.0rc1", 0},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := compareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
@@ -0,0 +1,483 @@
+package portsscanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/go-sql-driver/mysql"
+	"github.com/hirochachacha/go-smb2"
+	"github.com/jlaffaye/ftp"
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/ssh"
+)
+
+// errNoAuthRequired 服务无需认证，口令审计没有意义(由未授权访问检查负责)
+var errNoAuthRequired = errors.New("服务无需认证")
+
+func init() {
+	// 驱动在认证失败时会打印日志，审计时会产生大量输出
+	_ = mysql.SetLogger(log.New(io.Discard, "", 0))
+
+	registerCredentialService(&credentialService{
+		name:    "ssh",
+		users:   []string{"root", "admin", "ubuntu", "test", "oracle", "user"},
+		checker: checkSSH,
+	}, "ssh")
+	registerCredentialService(&credentialService{
+		name:    "ftp",
+		users:   []string{"ftp", "admin", "root", "www", "test", "user"},
+		checker: checkFTP,
+	}, "ftp")
+	registerCredentialService(&credentialService{
+		name:    "mysql",
+		users:   []string{"root", "mysql", "admin", "test"},
+		checker: checkMySQL,
+	}, "mysql")
+	registerCredentialService(&credentialService{
+		name:    "postgresql",
+		users:   []string{"postgres", "admin", "root"},
+		checker: checkPostgreSQL,
+	}, "postgresql", "postgres")
+	registerCredentialService(&credentialService{
+		name:    "redis",
+		users:   []string{""}, // Redis 6 之前只有口令
+		checker: checkRedis,
+	}, "redis")
+	registerCredentialService(&credentialService{
+		name:    "mssql",
+		users:   []string{"sa", "admin", "sql"},
+		checker: checkMSSQL,
+		delay:   200 * time.Millisecond,
+	}, "ms-sql-s", "mssql")
+	registerCredentialService(&credentialService{
+		name:    "mongodb",
+		users:   []string{"admin", "root", "mongo"},
+		checker: checkMongoDB,
+	}, "mongodb")
+	// Windows 账户默认开启锁定策略，串行且放慢尝试
+	registerCredentialService(&credentialService{
+		name:    "smb",
+		users:   []string{"administrator", "admin", "guest", "test"},
+		checker: checkSMB,
+		threads: 1,
+		delay:   time.Second,
+	}, "microsoft-ds", "smb")
+	registerCredentialService(&credentialService{
+		name:    "telnet",
+		users:   []string{"root", "admin", "user", "guest"},
+		checker: checkTelnet,
+		threads: 2,
+	}, "telnet")
+}
+
+func checkSSH(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	config := &ssh.ClientConfig{
+		User: user,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(pass),
+			ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = pass
+				}
+				return answers, nil
+			}),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         timeout,
+	}
+
+	conn, err := dialContext(ctx, host, port, timeout)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, net.JoinHostPort(host, strconv.Itoa(port)), config)
+	if err != nil {
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return false, nil
+		}
+		return false, err
+	}
+	ssh.NewClient(sshConn, chans, reqs).Close()
+	return true, nil
+}
+
+func checkFTP(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	conn, err := ftp.Dial(net.JoinHostPort(host, strconv.Itoa(port)), ftp.DialWithTimeout(timeout), ftp.DialWithContext(ctx))
+	if err != nil {
+		return false, err
+	}
+	defer conn.Quit()
+
+	if err := conn.Login(user, pass); err != nil {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code == ftp.StatusNotLoggedIn {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func checkMySQL(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	config := mysql.NewConfig()
+	config.User = user
+	config.Passwd = pass
+	config.Net = "tcp"
+	config.Addr = net.JoinHostPort(host, strconv.Itoa(port))
+	config.Timeout = timeout
+	config.ReadTimeout = timeout
+	config.AllowNativePasswords = true
+	config.AllowCleartextPasswords = false
+
+	connector, err := mysql.NewConnector(config)
+	if err != nil {
+		return false, err
+	}
+	err = pingDB(ctx, sql.OpenDB(connector), timeout)
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1045 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func checkPostgreSQL(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	dsn := (&url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(user, pass),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/postgres",
+		RawQuery: fmt.Sprintf("sslmode=disable&connect_timeout=%d", int(timeout.Seconds()+0.5)),
+	}).String()
+
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return false, err
+	}
+	err = pingDB(ctx, sql.OpenDB(connector), timeout)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "28P01", "28000": // invalid_password / invalid_authorization_specification
+			return false, nil
+		case "3D000": // 认证已通过，只是默认库不存在
+			return true, nil
+		}
+	}
+	return err == nil, err
+}
+
+func checkMSSQL(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	query := url.Values{}
+	query.Set("dial timeout", strconv.Itoa(int(timeout.Seconds()+0.5)))
+	query.Set("encrypt", "disable")
+	dsn := (&url.URL{
+		Scheme:   "sqlserver",
+		User:     url.UserPassword(user, pass),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		RawQuery: query.Encode(),
+	}).String()
+
+	connector, err := mssql.NewConnector(dsn)
+	if err != nil {
+		return false, err
+	}
+	err = pingDB(ctx, sql.OpenDB(connector), timeout)
+
+	var mssqlErr mssql.Error
+	if errors.As(err, &mssqlErr) {
+		switch mssqlErr.Number {
+		case 18456: // Login failed
+			return false, nil
+		case 18486: // 账户已锁定
+			return false, errCredentialLocked
+		}
+	}
+	return err == nil, err
+}
+
+func pingDB(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return db.PingContext(ctx)
+}
+
+func checkRedis(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	conn, err := dialContext(ctx, host, port, timeout)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	args := []string{"AUTH", pass}
+	if user != "" {
+		args = []string{"AUTH", user, pass}
+	}
+	reply, err := redisCommand(conn, args...)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case strings.HasPrefix(reply, "+OK"):
+		return true, nil
+	case strings.Contains(reply, "no password is set") || strings.Contains(reply, "without any password configured"):
+		return false, errNoAuthRequired
+	case strings.HasPrefix(reply, "-"):
+		return false, nil
+	}
+	return false, fmt.Errorf("非预期的 Redis 响应: %s", reply)
+}
+
+// redisCommand 以 RESP 协议发送命令并读取一行响应
+func redisCommand(conn net.Conn, args ...string) (string, error) {
+	if err := writeRESP(conn, args...); err != nil {
+		return "", err
+	}
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func writeRESP(conn net.Conn, args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := conn.Write([]byte(b.String()))
+	return err
+}
+
+func checkMongoDB(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	clientOptions := options.Client().
+		SetHosts([]string{net.JoinHostPort(host, strconv.Itoa(port))}).
+		SetDirect(true).
+		SetConnectTimeout(timeout).
+		SetServerSelectionTimeout(timeout).
+		SetAuth(options.Credential{Username: user, Password: pass, AuthSource: "admin"})
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return false, err
+	}
+	defer client.Disconnect(context.Background())
+
+	err = client.Ping(ctx, nil)
+	if err == nil {
+		return true, nil
+	}
+	if strings.Contains(err.Error(), "Authentication failed") || strings.Contains(err.Error(), "AuthenticationFailed") {
+		return false, nil
+	}
+	return false, err
+}
+
+// SMB 认证相关的 NTSTATUS
+const (
+	ntStatusLogonFailure       = 0xC000006D
+	ntStatusAccountRestriction = 0xC000006E
+	ntStatusAccountDisabled    = 0xC0000072
+	ntStatusAccountLockedOut   = 0xC0000234
+	ntStatusPasswordExpired    = 0xC0000071 // 口令正确但已过期
+	ntStatusMustChange         = 0xC0000224 // 口令正确但须修改
+)
+
+func checkSMB(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	conn, err := dialContext(ctx, host, port, timeout)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	dialer := &smb2.Dialer{
+		Initiator: &smb2.NTLMInitiator{User: user, Password: pass},
+	}
+	session, err := dialer.DialContext(ctx, conn)
+	if err == nil {
+		_ = session.Logoff()
+		return true, nil
+	}
+
+	var respErr *smb2.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.Code {
+		case ntStatusLogonFailure, ntStatusAccountRestriction, ntStatusAccountDisabled:
+			return false, nil
+		case ntStatusAccountLockedOut:
+			return false, errCredentialLocked
+		case ntStatusPasswordExpired, ntStatusMustChange:
+			return true, nil
+		}
+	}
+	return false, err
+}
+
+// Telnet 协商命令
+const (
+	telnetIAC  = 255
+	telnetDONT = 254
+	telnetDO   = 253
+	telnetWONT = 252
+	telnetWILL = 251
+	telnetSB   = 250
+	telnetSE   = 240
+)
+
+var (
+	telnetLoginPrompts    = []string{"login:", "username:", "user name:", "user:"}
+	telnetPasswordPrompts = []string{"password:", "passwd:"}
+	telnetFailureWords    = []string{"incorrect", "failed", "denied", "invalid", "bad password", "error", "username:"}
+	telnetShellPrompts    = []string{"$", "#", ">", "%"}
+)
+
+func checkTelnet(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error) {
+	conn, err := dialContext(ctx, host, port, timeout)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	prompt, err := telnetReadUntil(conn, timeout, append(telnetLoginPrompts, telnetPasswordPrompts...))
+	if err != nil {
+		return false, err
+	}
+	if containsAny(prompt, telnetLoginPrompts) {
+		if _, err := conn.Write([]byte(user + "\r\n")); err != nil {
+			return false, err
+		}
+		if _, err := telnetReadUntil(conn, timeout, telnetPasswordPrompts); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := conn.Write([]byte(pass + "\r\n")); err != nil {
+		return false, err
+	}
+
+	// 登录结果没有统一格式，读取一段时间后根据提示符判断
+	reply, _ := telnetReadUntil(conn, timeout, nil)
+	// 先判断成功标志，登录成功后的 "Last login: ..." 与欢迎信息中可能包含失败关键词
+	reply = strings.TrimSpace(reply)
+	if reply == "" {
+		return false, nil
+	}
+	if strings.Contains(reply, "last login") {
+		return true, nil
+	}
+	for _, p := range telnetShellPrompts {
+		if strings.HasSuffix(reply, p) {
+			return true, nil
+		}
+	}
+	if containsAny(reply, telnetFailureWords) {
+		return false, nil
+	}
+	return false, fmt.Errorf("无法识别的登录结果: %q", lastLine(reply))
+}
+
+func lastLine(text string) string {
+	return text[strings.LastIndex(text, "\n")+1:]
+}
+
+// telnetReadUntil 读取数据并拒绝所有选项协商，直到出现任一提示(不区分大小写)或超时。
+// prompts 为空时读取到超时为止
+func telnetReadUntil(conn net.Conn, timeout time.Duration, prompts []string) (string, error) {
+	deadline := time.Now().Add(timeout)
+	var text bytes.Buffer
+	buf := make([]byte, 1024)
+	for {
+		_ = conn.SetReadDeadline(deadline)
+		n, err := conn.Read(buf)
+		text.Write(telnetNegotiate(conn, buf[:n]))
+
+		lower := strings.ToLower(text.String())
+		if len(prompts) > 0 && containsAny(lower, prompts) {
+			return lower, nil
+		}
+		if err != nil {
+			if len(prompts) == 0 && isTimeout(err) {
+				return lower, nil
+			}
+			return lower, err
+		}
+	}
+}
+
+// telnetNegotiate 去除数据中的 IAC 命令，对 DO/WILL 一律回复 WONT/DONT
+func telnetNegotiate(conn net.Conn, data []byte) []byte {
+	var text, reply []byte
+	for i := 0; i < len(data); i++ {
+		if data[i] != telnetIAC || i+1 >= len(data) {
+			text = append(text, data[i])
+			continue
+		}
+		switch cmd := data[i+1]; cmd {
+		case telnetDO, telnetDONT, telnetWILL, telnetWONT:
+			if i+2 < len(data) {
+				opt := data[i+2]
+				if cmd == telnetDO {
+					reply = append(reply, telnetIAC, telnetWONT, opt)
+				} else if cmd == telnetWILL {
+					reply = append(reply, telnetIAC, telnetDONT, opt)
+				}
+			}
+			i += 2
+		case telnetSB:
+			for i < len(data) && !(data[i] == telnetIAC && i+1 < len(data) && data[i+1] == telnetSE) {
+				i++
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	if len(reply) > 0 {
+		_, _ = conn.Write(reply)
+	}
+	return text
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func dialContext(ctx context.Context, host string, port int, timeout time.Duration) (net.Conn, error) {
+	dialer := net.Dialer{Timeout: timeout}
+	return dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+}
@@ -0,0 +1,274 @@
+package portsscanner
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed credentials/passwords.txt
+var defaultPasswordList string
+
+// 口令审计默认参数
+const (
+	defaultCredentialThreads  = 4  // 单个服务实例的并发数
+	defaultCredentialParallel = 10 // 同时审计的服务实例数
+	// 连续连接失败达到该次数时放弃该服务实例，避免对不可用的服务空耗时间
+	maxCredentialFailures = 3
+)
+
+// WeakCredential 发现的弱口令
+type WeakCredential struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Service  string `json:"service"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type CredentialCallback func(WeakCredential)
+
+// CredentialConfig 口令审计参数，仅用于已获授权的安全评估
+type CredentialConfig struct {
+	Users     []string      // 为空时使用各服务内置的默认用户名
+	Passwords []string      // 为空时使用内置弱口令字典，%user% 会替换为用户名
+	Threads   int           // 单个服务实例的并发数，默认 4
+	Parallel  int           // 同时审计的服务实例数，默认 10
+	Delay     time.Duration // 同一服务实例两次尝试之间的最小间隔，为 0 时使用服务的默认间隔
+	Timeout   time.Duration
+}
+
+// errCredentialLocked 服务端提示账户已锁定，立即停止对该服务实例的尝试
+var errCredentialLocked = errors.New("账户已锁定")
+
+// credentialChecker 尝试一组用户名与口令，认证失败返回 false, nil，连接或协议错误返回 error
+type credentialChecker func(ctx context.Context, host string, port int, user, pass string, timeout time.Duration) (bool, error)
+
+type credentialService struct {
+	name    string
+	users   []string
+	checker credentialChecker
+	threads int           // 覆盖默认并发数，容易触发锁定的服务为 1
+	delay   time.Duration // 默认尝试间隔
+}
+
+// credentialServices 按 gonmap 识别出的服务名索引
+var credentialServices = map[string]*credentialService{}
+
+func registerCredentialService(service *credentialService, names ...string) {
+	for _, name := range names {
+		credentialServices[name] = service
+	}
+}
+
+// LoadWordList 读取用户名或口令字典文件，每行一条，忽略空行与 # 开头的注释
+func LoadWordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开字典文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("读取字典文件失败: %w", err)
+	}
+	return words, nil
+}
+
+// defaultPasswords 返回内置弱口令，首项为空口令
+func defaultPasswords() []string {
+	passwords := []string{""}
+	for _, line := range strings.Split(defaultPasswordList, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, line)
+	}
+	return passwords
+}
+
+// AuditCredentials 对支持的服务尝试弱口令，每个服务实例找到一组有效口令后即停止
+func AuditCredentials(ctx context.Context, ports []PortInfo, config CredentialConfig, callback CredentialCallback) error {
+	if callback == nil {
+		return fmt.Errorf("callback function cannot be nil")
+	}
+	if config.Threads <= 0 {
+		config.Threads = defaultCredentialThreads
+	}
+	if config.Parallel <= 0 {
+		config.Parallel = defaultCredentialParallel
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if len(config.Passwords) == 0 {
+		config.Passwords = defaultPasswords()
+	}
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, config.Parallel)
+	for _, portInfo := range ports {
+		service, ok := credentialServices[strings.ToLower(portInfo.Service)]
+		if !ok || portInfo.State != StateOpen {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return context.Canceled
+		case semaphore <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(portInfo PortInfo, service *credentialService) {
+			defer func() {
+				wg.Done()
+				<-semaphore
+			}()
+			if cred, ok := auditService(ctx, portInfo, service, config); ok {
+				callback(cred)
+			}
+		}(portInfo, service)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return context.Canceled
+	}
+	return nil
+}
+
+type credentialPair struct {
+	user, pass string
+}
+
+// auditService 对单个服务实例按用户名、口令顺序尝试，
+// 尝试之间保持间隔，出现锁定提示或连续连接失败时放弃
+func auditService(ctx context.Context, portInfo PortInfo, service *credentialService, config CredentialConfig) (WeakCredential, bool) {
+	users := config.Users
+	if len(users) == 0 {
+		users = service.users
+	}
+	threads := config.Threads
+	if service.threads > 0 && service.threads < threads {
+		threads = service.threads
+	}
+	delay := config.Delay
+	if delay <= 0 {
+		delay = service.delay
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pairs := make(chan credentialPair)
+	go func() {
+		defer close(pairs)
+		seen := make(map[credentialPair]bool)
+		for _, user := range users {
+			for _, pass := range config.Passwords {
+				pair := credentialPair{user, strings.ReplaceAll(pass, "%user%", user)}
+				if seen[pair] {
+					continue
+				}
+				seen[pair] = true
+				select {
+				case pairs <- pair:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		found    WeakCredential
+		ok       bool
+		failures int
+		last     time.Time
+	)
+
+	// wait 保证同一服务实例的相邻两次尝试间隔不小于 delay
+	wait := func() bool {
+		if delay <= 0 {
+			return true
+		}
+		mu.Lock()
+		next := last.Add(delay)
+		if now := time.Now(); next.Before(now) {
+			next = now
+		}
+		last = next
+		mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for pair := range pairs {
+				if ctx.Err() != nil || !wait() {
+					return
+				}
+
+				success, err := service.checker(ctx, portInfo.Host, portInfo.Port, pair.user, pair.pass, config.Timeout)
+				mu.Lock()
+				switch {
+				case success:
+					if !ok {
+						ok = true
+						found = WeakCredential{
+							Host:     portInfo.Host,
+							Port:     portInfo.Port,
+							Service:  service.name,
+							Username: pair.user,
+							Password: pair.pass,
+						}
+					}
+					cancel()
+				case errors.Is(err, errCredentialLocked), errors.Is(err, errNoAuthRequired):
+					cancel()
+				case err != nil:
+					failures++
+					if failures >= maxCredentialFailures {
+						cancel()
+					}
+				default:
+					failures = 0
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	return found, ok
+}
@@ -0,0 +1,401 @@
+package portsscanner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lcvvvv/gonmap"
+	"gopkg.in/yaml.v3"
+)
+
+// 自定义探针文件格式，按扩展名区分: .yaml / .yml 为 YAML 指纹规则，其余为 nmap-service-probes 格式
+const (
+	ProbeFormatNmap = "nmap"
+	ProbeFormatYAML = "yaml"
+)
+
+// ProbeFileInfo 自定义探针文件的解析结果
+type ProbeFileInfo struct {
+	Name    string            `json:"name"`
+	Path    string            `json:"path"`
+	Format  string            `json:"format"`
+	Probes  int               `json:"probes"`  // 文件中定义或追加规则的探针数
+	Matches int               `json:"matches"` // match 与 softmatch 规则数
+	Errors  []ProbeParseError `json:"errors"`
+}
+
+// probeRule YAML 格式的一条指纹规则。设置 send 时定义一个新探针，
+// 否则把规则追加到已有的探针上(默认 NULL，即连接后被动读取的 Banner)
+type probeRule struct {
+	Probe    string `yaml:"probe"`    // 探针名，不含 TCP_ / UDP_ 前缀
+	Protocol string `yaml:"protocol"` // tcp / udp，默认 tcp
+	Send     string `yaml:"send"`     // 探针载荷，支持 \r \n \xHH 等转义
+	Ports    string `yaml:"ports"`
+	SSLPorts string `yaml:"sslports"`
+	Service  string `yaml:"service"`
+	Pattern  string `yaml:"pattern"`
+	Flags    string `yaml:"flags"` // 正则选项 i / s
+	Soft     bool   `yaml:"soft"`  // 只确定服务名，继续尝试同服务的其他规则
+	Product  string `yaml:"product"`
+	Version  string `yaml:"version"`
+	Info     string `yaml:"info"`
+	Hostname string `yaml:"hostname"`
+	OS       string `yaml:"os"`
+	Device   string `yaml:"device"`
+	CPE      string `yaml:"cpe"`
+}
+
+var (
+	customProbesMutex sync.Mutex
+	customProbes      *probeDB            // 全部自定义探针，nil 表示尚未加载
+	mergedProbes      map[string]*probeDB // 按协议缓存的内置与自定义合并结果
+)
+
+var yamlErrorLineRegexp = regexp.MustCompile(`line (\d+)`)
+
+func probeFileDir() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "probes")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建探针目录失败: %w", err)
+	}
+	return dir, nil
+}
+
+// ValidateProbeFile 解析探针文件并返回其中的探针、规则数量与逐行的解析错误，不会加载文件
+func ValidateProbeFile(path string) (ProbeFileInfo, error) {
+	_, info, err := parseProbeFile(path)
+	return info, err
+}
+
+// AddProbeFile 校验探针文件后复制到数据目录，与内置探针库合并用于之后的指纹识别
+func AddProbeFile(path string) (ProbeFileInfo, error) {
+	_, info, err := parseProbeFile(path)
+	if err != nil {
+		return info, err
+	}
+	if len(info.Errors) > 0 {
+		return info, fmt.Errorf("探针文件有 %d 处错误，%s", len(info.Errors), info.Errors[0])
+	}
+
+	dir, err := probeFileDir()
+	if err != nil {
+		return info, err
+	}
+	dst := filepath.Join(dir, info.Name)
+	if err := copyFile(path, dst); err != nil {
+		return info, fmt.Errorf("保存探针文件失败: %w", err)
+	}
+	info.Path = dst
+
+	reloadCustomProbes()
+	return info, nil
+}
+
+// ListProbeFiles 列出已加载的自定义探针文件
+func ListProbeFiles() ([]ProbeFileInfo, error) {
+	dir, err := probeFileDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ProbeFileInfo, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		_, info, err := parseProbeFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// RemoveProbeFile 删除自定义探针文件，name 为 ListProbeFiles 返回的文件名
+func RemoveProbeFile(name string) error {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("无效的探针文件名: %s", name)
+	}
+	dir, err := probeFileDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	reloadCustomProbes()
+	return nil
+}
+
+func parseProbeFile(path string) (*probeDB, ProbeFileInfo, error) {
+	info := ProbeFileInfo{Name: filepath.Base(path), Path: path, Format: ProbeFormatNmap}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, info, fmt.Errorf("读取探针文件失败: %w", err)
+	}
+
+	var db *probeDB
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		info.Format = ProbeFormatYAML
+		db, info.Errors = parseProbeRules(data)
+	default:
+		db, info.Errors = parseServiceProbes(string(data), "")
+	}
+
+	info.Probes = len(db.probes)
+	for _, p := range db.probes {
+		info.Matches += len(p.matches)
+	}
+	return db, info, nil
+}
+
+// parseProbeRules 解析 YAML 指纹规则列表，无效的规则跳过并记录所在行
+func parseProbeRules(data []byte) (*probeDB, []ProbeParseError) {
+	db := &probeDB{byName: make(map[string]*serviceProbe)}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		line := 0
+		if m := yamlErrorLineRegexp.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return db, []ProbeParseError{{Line: line, Message: err.Error()}}
+	}
+	if len(root.Content) == 0 {
+		return db, nil
+	}
+	list := root.Content[0]
+	if list.Kind != yaml.SequenceNode {
+		return db, []ProbeParseError{{Line: list.Line, Message: "规则文件应为规则列表"}}
+	}
+
+	var errs []ProbeParseError
+	for _, node := range list.Content {
+		var rule probeRule
+		if err := node.Decode(&rule); err != nil {
+			errs = append(errs, ProbeParseError{Line: node.Line, Message: err.Error()})
+			continue
+		}
+		if err := db.addRule(rule); err != nil {
+			errs = append(errs, ProbeParseError{Line: node.Line, Message: err.Error()})
+		}
+	}
+	return db, errs
+}
+
+// addRule 将一条 YAML 规则转换为探针与匹配规则
+func (db *probeDB) addRule(rule probeRule) error {
+	if rule.Service == "" || rule.Pattern == "" {
+		return fmt.Errorf("规则缺少 service 或 pattern")
+	}
+	protocol := strings.ToUpper(rule.Protocol)
+	if protocol == "" {
+		protocol = "TCP"
+	}
+	if protocol != "TCP" && protocol != "UDP" {
+		return fmt.Errorf("无效的 protocol: %s", rule.Protocol)
+	}
+	if strings.Trim(rule.Flags, "is") != "" {
+		return fmt.Errorf("无效的 flags: %s，仅支持 i / s", rule.Flags)
+	}
+	if rule.Probe == "" {
+		if rule.Send != "" {
+			return fmt.Errorf("设置 send 时需要指定 probe 名称")
+		}
+		rule.Probe = "NULL"
+	}
+
+	re, err := compileProbePattern(rule.Pattern, rule.Flags)
+	if err != nil {
+		return fmt.Errorf("pattern 无法编译: %v", err)
+	}
+	m := &serviceMatch{
+		soft:    rule.Soft,
+		service: gonmap.FixProtocol(rule.Service),
+		re:      re,
+		versionInfo: map[string]string{
+			"p": rule.Product, "v": rule.Version, "i": rule.Info,
+			"h": rule.Hostname, "o": rule.OS, "d": rule.Device, "cpe": rule.CPE,
+		},
+	}
+
+	name := protocol + "_" + rule.Probe
+	p := &serviceProbe{protocol: protocol, name: name, rarity: 1}
+	if rule.Send == "" {
+		if rule.Ports != "" || rule.SSLPorts != "" {
+			return fmt.Errorf("ports / sslports 仅在设置 send 时有效")
+		}
+		// 追加到已有探针时必须能找到该探针
+		if _, ok := db.byName[name]; !ok {
+			if _, ok := builtinProbeDB(protocol).byName[name]; !ok {
+				return fmt.Errorf("探针 %s 不存在，定义新探针需要设置 send", rule.Probe)
+			}
+		}
+	} else {
+		if p.payload, err = unescapeProbeString(rule.Send); err != nil {
+			return fmt.Errorf("send 无效: %w", err)
+		}
+		if p.ports, err = parsePortRanges(rule.Ports); err != nil {
+			return fmt.Errorf("ports 无效: %w", err)
+		}
+		if p.sslports, err = parsePortRanges(rule.SSLPorts); err != nil {
+			return fmt.Errorf("sslports 无效: %w", err)
+		}
+	}
+	p.matches = []*serviceMatch{m}
+	db.add(p)
+	return nil
+}
+
+// loadCustomProbes 读取数据目录中的全部探针文件，单个文件中的无效行被跳过
+func loadCustomProbes() *probeDB {
+	db := &probeDB{byName: make(map[string]*serviceProbe)}
+	dir, err := probeFileDir()
+	if err != nil {
+		return db
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return db
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file, _, err := parseProbeFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		for _, p := range file.probes {
+			db.add(p)
+		}
+	}
+	return db
+}
+
+func reloadCustomProbes() {
+	db := loadCustomProbes()
+	customProbesMutex.Lock()
+	customProbes = db
+	mergedProbes = make(map[string]*probeDB)
+	customProbesMutex.Unlock()
+}
+
+// withCustomProbes 返回内置探针与自定义探针合并后的探针库，首次调用时加载自定义探针
+func withCustomProbes(protocol string) *probeDB {
+	customProbesMutex.Lock()
+	defer customProbesMutex.Unlock()
+	if customProbes == nil {
+		customProbes = loadCustomProbes()
+		mergedProbes = make(map[string]*probeDB)
+	}
+	if db, ok := mergedProbes[protocol]; ok {
+		return db
+	}
+	db := mergeProbeDB(builtinProbeDB(protocol), customProbes, protocol)
+	mergedProbes[protocol] = db
+	return db
+}
+
+// mergeProbeDB 合并内置与自定义探针。与内置探针同名时沿用内置的载荷与端口，自定义规则排在前面优先匹配；
+// 新探针排在同 rarity 的内置探针之前
+func mergeProbeDB(base, custom *probeDB, protocol string) *probeDB {
+	if len(custom.probes) == 0 {
+		return base
+	}
+
+	db := &probeDB{byName: make(map[string]*serviceProbe)}
+	for _, p := range custom.probes {
+		if p.protocol != protocol {
+			continue
+		}
+		merged := *p
+		if b, ok := base.byName[p.name]; ok {
+			merged = *b
+			merged.matches = append(append([]*serviceMatch(nil), p.matches...), b.matches...)
+		}
+		db.byName[p.name] = &merged
+		db.probes = append(db.probes, &merged)
+	}
+	for _, p := range base.probes {
+		if _, ok := db.byName[p.name]; !ok {
+			db.byName[p.name] = p
+			db.probes = append(db.probes, p)
+		}
+	}
+
+	sort.SliceStable(db.probes, func(i, j int) bool {
+		return db.probes[i].rarity < db.probes[j].rarity
+	})
+	return db
+}
+
+// customTCPProbes 返回适用于端口的自定义 TCP 探针(内置探针库中没有的)，未指定端口的探针适用于全部端口
+func customTCPProbes(port int) []*serviceProbe {
+	db := tcpProbeDB()
+	builtin := builtinProbeDB("TCP")
+
+	customProbesMutex.Lock()
+	defer customProbesMutex.Unlock()
+	var result []*serviceProbe
+	for _, p := range customProbes.probes {
+		if p.protocol != "TCP" || len(p.payload) == 0 || builtin.byName[p.name] != nil {
+			continue
+		}
+		if len(p.ports) > 0 || len(p.sslports) > 0 {
+			if !containsPort(p.ports, port) && !containsPort(p.sslports, port) {
+				continue
+			}
+		}
+		result = append(result, db.byName[p.name])
+	}
+	return result
+}
+
+// probeCustomTCP 在 deadline 前依次发送自定义 TCP 探针，命中指纹时填充 portInfo 并返回 true
+func probeCustomTCP(portInfo *PortInfo, host string, port int, serverName string, deadline time.Time) bool {
+	fp := sendTCPProbes(portInfo, tcpProbeDB(), customTCPProbes(port), host, port, serverName, deadline)
+	if fp == nil {
+		return false
+	}
+	fillFingerPrint(portInfo, fp)
+	return true
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,122 @@
+package portsscanner
+
+import (
+	"slices"
+	"testing"
+)
+
+const testProbeRules = `
+- service: redis
+  pattern: '^-ERR unknown command'
+  product: Redis key-value store
+
+- probe: Hello
+  send: 'HELLO\r\n\x00'
+  ports: 9000-9002,9100
+  sslports: 9443
+  service: myservice
+  pattern: '^hello from (\w+) v([\d.]+)'
+  flags: i
+  product: $1
+  version: $2
+
+- probe: Hello
+  service: myservice
+  pattern: '^HELLO'
+  soft: true
+
+- probe: Status
+  protocol: udp
+  send: 'STATUS'
+  ports: 5000
+  service: myudp
+  pattern: '^OK'
+`
+
+func TestParseProbeRules(t *testing.T) {
+	db, errs := parseProbeRules([]byte(testProbeRules))
+	if len(errs) > 0 {
+		t.Fatalf("parseProbeRules errors: %v", errs)
+	}
+
+	names := make([]string, 0, len(db.probes))
+	for _, p := range db.probes {
+		names = append(names, p.name)
+	}
+	if want := []string{"TCP_NULL", "TCP_Hello", "UDP_Status"}; !slices.Equal(names, want) {
+		t.Fatalf("probes = %v, want %v", names, want)
+	}
+
+	hello := db.byName["TCP_Hello"]
+	if string(hello.payload) != "HELLO\r\n\x00" {
+		t.Errorf("TCP_Hello payload = %q", hello.payload)
+	}
+	if want := []int{9000, 9001, 9002, 9100}; !slices.Equal(hello.ports, want) {
+		t.Errorf("TCP_Hello ports = %v, want %v", hello.ports, want)
+	}
+	if want := []int{9443}; !slices.Equal(hello.sslports, want) {
+		t.Errorf("TCP_Hello sslports = %v, want %v", hello.sslports, want)
+	}
+	if len(hello.matches) != 2 {
+		t.Errorf("TCP_Hello has %d matches, want 2", len(hello.matches))
+	}
+	if p := db.byName["UDP_Status"]; p.protocol != "UDP" || !slices.Equal(p.ports, []int{5000}) {
+		t.Errorf("UDP_Status = %s %v", p.protocol, p.ports)
+	}
+
+	tests := []struct {
+		probe    string
+		response string
+		service  string
+		product  string
+		version  string
+	}{
+		{"TCP_NULL", "-ERR unknown command 'foo'\r\n", "redis", "Redis key-value store", ""},
+		{"TCP_Hello", "Hello from Widget v2.4.1\n", "myservice", "Widget", "2.4.1"},
+		{"TCP_Hello", "HELLO?\n", "myservice", "", ""},
+		{"TCP_Hello", "bye\n", "", "", ""},
+		{"UDP_Status", "OK 1\n", "myudp", "", ""},
+	}
+	for _, tt := range tests {
+		fp := db.byName[tt.probe].match(tt.response)
+		if fp.Service != tt.service || fp.ProductName != tt.product || fp.Version != tt.version {
+			t.Errorf("%s match %q = %q %q %q, want %q %q %q", tt.probe, tt.response,
+				fp.Service, fp.ProductName, fp.Version, tt.service, tt.product, tt.version)
+		}
+	}
+}
+
+func TestParseProbeRulesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		lines []int
+	}{
+		{"not a list", "service: ssh\npattern: SSH\n", []int{1}},
+		{"invalid yaml", "- service: ssh\n  pattern: [\n", []int{2}},
+		{"missing pattern", "- service: ssh\n", []int{1}},
+		{"invalid protocol", "- service: ssh\n  pattern: SSH\n  protocol: sctp\n", []int{1}},
+		{"invalid flags", "- service: ssh\n  pattern: SSH\n  flags: m\n", []int{1}},
+		{"send without probe", "- service: ssh\n  pattern: SSH\n  send: hi\n", []int{1}},
+		{"unknown probe", "- probe: NoSuchProbe\n  service: ssh\n  pattern: SSH\n", []int{1}},
+		{"ports without send", "- service: ssh\n  pattern: SSH\n  ports: 22\n", []int{1}},
+		{"bad pattern", "- service: ssh\n  pattern: '(SSH'\n", []int{1}},
+		{"bad ports", "- probe: X\n  send: hi\n  ports: 0-10\n  service: ssh\n  pattern: SSH\n", []int{1}},
+		{"bad escape", "- probe: X\n  send: '\\xZZ'\n  service: ssh\n  pattern: SSH\n", []int{1}},
+		{
+			"valid rules are kept",
+			"- service: ssh\n  pattern: SSH\n- service: ftp\n- probe: GetRequest\n  service: http\n  pattern: HTTP\n",
+			[]int{3},
+		},
+	}
+	for _, tt := range tests {
+		_, errs := parseProbeRules([]byte(tt.data))
+		lines := make([]int, 0, len(errs))
+		for _, err := range errs {
+			lines = append(lines, err.Line)
+		}
+		if !slices.Equal(lines, tt.lines) {
+			t.Errorf("%s: error lines = %v (%v), want %v", tt.name, lines, errs, tt.lines)
+		}
+	}
+}
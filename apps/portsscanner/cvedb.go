@@ -0,0 +1,479 @@
+package portsscanner
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CVEMatch 端口服务命中的 CVE
+type CVEMatch struct {
+	ID       string  `json:"id"`
+	CVSS     float64 `json:"cvss"`
+	Severity string  `json:"severity"`
+	Evidence string  `json:"evidence"` // 命中的版本或版本范围
+}
+
+// CVEDatabaseInfo 本地 CVE 库概况
+type CVEDatabaseInfo struct {
+	Path     string    `json:"path"`
+	CVEs     int       `json:"cves"`
+	Products int       `json:"products"`
+	Updated  time.Time `json:"updated"`
+}
+
+// cveEntry 本地库中的一条 CVE，只保存受影响的 CPE 与版本范围
+type cveEntry struct {
+	ID       string       `json:"id"`
+	CVSS     float64      `json:"cvss"`
+	Severity string       `json:"severity"`
+	Affected []cveAffects `json:"affected"`
+}
+
+// cveAffects 对应 NVD 配置中的一条 vulnerable cpeMatch
+type cveAffects struct {
+	Product        string `json:"product"`           // vendor:product
+	Version        string `json:"version,omitempty"` // CPE 中的具体版本，为空表示由范围决定
+	StartIncluding string `json:"start_including,omitempty"`
+	StartExcluding string `json:"start_excluding,omitempty"`
+	EndIncluding   string `json:"end_including,omitempty"`
+	EndExcluding   string `json:"end_excluding,omitempty"`
+}
+
+// CVEDatabase 可离线使用的 CVE 库，由 NVD JSON 数据源导入
+type CVEDatabase struct {
+	Updated time.Time            `json:"updated"`
+	Entries map[string]*cveEntry `json:"entries"`
+
+	byProduct map[string][]*cveEntry
+}
+
+// DefaultCVEDatabasePath 返回本地 CVE 库的默认保存位置
+func DefaultCVEDatabasePath() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "cvedb.json.gz"), nil
+}
+
+// appDataDir 返回应用数据目录，不存在时创建
+func appDataDir() (string, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("获取用户配置目录失败: %w", err)
+	}
+	dir := filepath.Join(base, "GlideWay")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建数据目录失败: %w", err)
+	}
+	return dir, nil
+}
+
+// NewCVEDatabase 创建空的 CVE 库
+func NewCVEDatabase() *CVEDatabase {
+	db := &CVEDatabase{Entries: make(map[string]*cveEntry)}
+	db.reindex()
+	return db
+}
+
+// LoadCVEDatabase 读取本地 CVE 库，文件不存在时返回空库
+func LoadCVEDatabase(path string) (*CVEDatabase, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewCVEDatabase(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("打开 CVE 库失败: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("读取 CVE 库失败: %w", err)
+	}
+	defer reader.Close()
+
+	db := &CVEDatabase{}
+	if err := json.NewDecoder(reader).Decode(db); err != nil {
+		return nil, fmt.Errorf("解析 CVE 库失败: %w", err)
+	}
+	if db.Entries == nil {
+		db.Entries = make(map[string]*cveEntry)
+	}
+	db.reindex()
+	return db, nil
+}
+
+// Save 将 CVE 库以 gzip 压缩的 JSON 写入 path，先写临时文件再替换，避免中断时损坏原库
+func (db *CVEDatabase) Save(path string) error {
+	if err := writeJSONGzip(path, db); err != nil {
+		return fmt.Errorf("保存 CVE 库失败: %w", err)
+	}
+	return nil
+}
+
+// Info 返回 CVE 库概况
+func (db *CVEDatabase) Info() CVEDatabaseInfo {
+	return CVEDatabaseInfo{
+		CVEs:     len(db.Entries),
+		Products: len(db.byProduct),
+		Updated:  db.Updated,
+	}
+}
+
+func (db *CVEDatabase) reindex() {
+	db.byProduct = make(map[string][]*cveEntry)
+	for _, entry := range db.Entries {
+		seen := make(map[string]bool)
+		for _, affects := range entry.Affected {
+			if !seen[affects.Product] {
+				seen[affects.Product] = true
+				db.byProduct[affects.Product] = append(db.byProduct[affects.Product], entry)
+			}
+		}
+	}
+}
+
+// Match 返回影响该 CPE 版本的 CVE，按 CVSS 降序。未识别出版本时不做匹配，避免大量误报
+func (db *CVEDatabase) Match(cpe CPE) []CVEMatch {
+	if cpe.Version == "" {
+		return nil
+	}
+
+	var matches []CVEMatch
+	seen := make(map[string]bool)
+	for _, key := range append([]string{cpe.key()}, cpeAliases[cpe.key()]...) {
+		for _, entry := range db.byProduct[key] {
+			if seen[entry.ID] {
+				continue
+			}
+			for _, affects := range entry.Affected {
+				if affects.Product != key {
+					continue
+				}
+				if evidence, ok := affects.match(cpe.Version); ok {
+					seen[entry.ID] = true
+					matches = append(matches, CVEMatch{
+						ID:       entry.ID,
+						CVSS:     entry.CVSS,
+						Severity: entry.Severity,
+						Evidence: evidence,
+					})
+					break
+				}
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].CVSS != matches[j].CVSS {
+			return matches[i].CVSS > matches[j].CVSS
+		}
+		return matches[i].ID > matches[j].ID
+	})
+	return matches
+}
+
+// match 判断版本是否受影响，返回版本比较依据
+func (a cveAffects) match(version string) (string, bool) {
+	if a.Version != "" {
+		// 7.4p1 命中 NVD 中 update 字段为 p1 的 7.4
+		if compareVersions(version, a.Version) == 0 || isUpdateOf(version, a.Version) {
+			return fmt.Sprintf("%s %s 与受影响版本 %s 一致", a.Product, version, a.Version), true
+		}
+		return "", false
+	}
+
+	var bounds []string
+	if a.StartIncluding != "" {
+		if compareVersions(version, a.StartIncluding) < 0 {
+			return "", false
+		}
+		bounds = append(bounds, ">= "+a.StartIncluding)
+	}
+	if a.StartExcluding != "" {
+		if compareVersions(version, a.StartExcluding) <= 0 {
+			return "", false
+		}
+		bounds = append(bounds, "> "+a.StartExcluding)
+	}
+	if a.EndIncluding != "" {
+		// NVD 的版本号不含补丁号，<= 7.4 同样覆盖 7.4p1
+		if compareVersions(version, a.EndIncluding) > 0 && !isUpdateOf(version, a.EndIncluding) {
+			return "", false
+		}
+		bounds = append(bounds, "<= "+a.EndIncluding)
+	}
+	if a.EndExcluding != "" {
+		if compareVersions(version, a.EndExcluding) >= 0 {
+			return "", false
+		}
+		bounds = append(bounds, "< "+a.EndExcluding)
+	}
+	// 既无具体版本也无范围表示所有版本受影响，这类记录多为产品级公告，不作为证据
+	if len(bounds) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%s %s 在受影响范围 %s 内", a.Product, version, strings.Join(bounds, ", ")), true
+}
+
+// isUpdateOf 判断 version 是否为 base 追加字母补丁号的形式，如 7.4p1 之于 7.4
+func isUpdateOf(version, base string) bool {
+	rest, ok := strings.CutPrefix(version, base)
+	return ok && rest != "" && (rest[0] < '0' || rest[0] > '9') && rest[0] != '.'
+}
+
+// ImportFeed 导入 NVD JSON 数据源(支持 1.1 版 CVE_Items 与 2.0 版 vulnerabilities，可为 .gz 压缩)，
+// 同一 CVE 以后导入的为准，返回导入的条目数
+func (db *CVEDatabase) ImportFeed(path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("打开数据源失败: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return 0, fmt.Errorf("解压数据源失败: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+	}
+
+	var feed nvdFeed
+	if err := json.NewDecoder(reader).Decode(&feed); err != nil {
+		return 0, fmt.Errorf("解析数据源失败: %w", err)
+	}
+
+	count := 0
+	add := func(entry *cveEntry) {
+		if entry.ID == "" || len(entry.Affected) == 0 {
+			return
+		}
+		db.Entries[entry.ID] = entry
+		count++
+	}
+	for _, item := range feed.LegacyItems {
+		add(item.entry())
+	}
+	for _, item := range feed.Vulnerabilities {
+		add(item.CVE.entry())
+	}
+	for _, item := range feed.CVEItems {
+		add(item.entry())
+	}
+	if count == 0 && len(feed.LegacyItems)+len(feed.Vulnerabilities)+len(feed.CVEItems) == 0 {
+		return 0, fmt.Errorf("不是有效的 NVD JSON 数据源: %s", filepath.Base(path))
+	}
+
+	db.Updated = time.Now()
+	db.reindex()
+	return count, nil
+}
+
+// nvdFeed 兼容 NVD 1.1 数据源、2.0 数据源/API 响应以及按年拆分的 2.0 镜像(cve_items)
+type nvdFeed struct {
+	LegacyItems     []nvdLegacyItem `json:"CVE_Items"`
+	Vulnerabilities []struct {
+		CVE nvdCVE `json:"cve"`
+	} `json:"vulnerabilities"`
+	CVEItems []nvdCVE `json:"cve_items"`
+}
+
+type nvdLegacyItem struct {
+	CVE struct {
+		Meta struct {
+			ID string `json:"ID"`
+		} `json:"CVE_data_meta"`
+	} `json:"cve"`
+	Configurations struct {
+		Nodes []nvdLegacyNode `json:"nodes"`
+	} `json:"configurations"`
+	Impact struct {
+		V3 struct {
+			CVSS struct {
+				BaseScore    float64 `json:"baseScore"`
+				BaseSeverity string  `json:"baseSeverity"`
+			} `json:"cvssV3"`
+		} `json:"baseMetricV3"`
+		V2 struct {
+			CVSS struct {
+				BaseScore float64 `json:"baseScore"`
+			} `json:"cvssV2"`
+			Severity string `json:"severity"`
+		} `json:"baseMetricV2"`
+	} `json:"impact"`
+}
+
+type nvdLegacyNode struct {
+	Children []nvdLegacyNode `json:"children"`
+	Matches  []struct {
+		Vulnerable bool   `json:"vulnerable"`
+		URI        string `json:"cpe23Uri"`
+		nvdVersionRange
+	} `json:"cpe_match"`
+}
+
+type nvdVersionRange struct {
+	StartIncluding string `json:"versionStartIncluding"`
+	StartExcluding string `json:"versionStartExcluding"`
+	EndIncluding   string `json:"versionEndIncluding"`
+	EndExcluding   string `json:"versionEndExcluding"`
+}
+
+func (item nvdLegacyItem) entry() *cveEntry {
+	entry := &cveEntry{ID: item.CVE.Meta.ID}
+	if v3 := item.Impact.V3.CVSS; v3.BaseScore > 0 {
+		entry.CVSS, entry.Severity = v3.BaseScore, strings.ToLower(v3.BaseSeverity)
+	} else {
+		entry.CVSS, entry.Severity = item.Impact.V2.CVSS.BaseScore, strings.ToLower(item.Impact.V2.Severity)
+	}
+
+	var walk func(nodes []nvdLegacyNode)
+	walk = func(nodes []nvdLegacyNode) {
+		for _, node := range nodes {
+			for _, m := range node.Matches {
+				if m.Vulnerable {
+					entry.addAffects(m.URI, m.nvdVersionRange)
+				}
+			}
+			walk(node.Children)
+		}
+	}
+	walk(item.Configurations.Nodes)
+	return entry
+}
+
+type nvdCVE struct {
+	ID      string `json:"id"`
+	Metrics struct {
+		V31 []nvdMetric `json:"cvssMetricV31"`
+		V30 []nvdMetric `json:"cvssMetricV30"`
+		V2  []nvdMetric `json:"cvssMetricV2"`
+	} `json:"metrics"`
+	Configurations []struct {
+		Nodes []struct {
+			Matches []struct {
+				Vulnerable bool   `json:"vulnerable"`
+				Criteria   string `json:"criteria"`
+				nvdVersionRange
+			} `json:"cpeMatch"`
+		} `json:"nodes"`
+	} `json:"configurations"`
+}
+
+type nvdMetric struct {
+	Data struct {
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"` // CVSS v3
+	} `json:"cvssData"`
+	BaseSeverity string `json:"baseSeverity"` // CVSS v2
+}
+
+func (cve nvdCVE) entry() *cveEntry {
+	entry := &cveEntry{ID: cve.ID}
+	// 优先使用 CVSS v3.1，其次 v3.0、v2
+	for _, metrics := range [][]nvdMetric{cve.Metrics.V31, cve.Metrics.V30, cve.Metrics.V2} {
+		if len(metrics) == 0 {
+			continue
+		}
+		entry.CVSS = metrics[0].Data.BaseScore
+		entry.Severity = strings.ToLower(metrics[0].Data.BaseSeverity)
+		if entry.Severity == "" {
+			entry.Severity = strings.ToLower(metrics[0].BaseSeverity)
+		}
+		break
+	}
+
+	for _, config := range cve.Configurations {
+		for _, node := range config.Nodes {
+			for _, m := range node.Matches {
+				if m.Vulnerable {
+					entry.addAffects(m.Criteria, m.nvdVersionRange)
+				}
+			}
+		}
+	}
+	return entry
+}
+
+// addAffects 记录受影响的 CPE。与操作系统等组合的 AND 配置按其中的应用单独匹配，宁可多报
+func (entry *cveEntry) addAffects(uri string, r nvdVersionRange) {
+	cpe, ok := parseCPE(uri)
+	if !ok {
+		return
+	}
+	entry.Affected = append(entry.Affected, cveAffects{
+		Product:        cpe.key(),
+		Version:        cpe.Version,
+		StartIncluding: r.StartIncluding,
+		StartExcluding: r.StartExcluding,
+		EndIncluding:   r.EndIncluding,
+		EndExcluding:   r.EndExcluding,
+	})
+}
+
+var (
+	sharedCVEDB      *CVEDatabase
+	sharedCVEDBMutex sync.Mutex
+)
+
+// loadSharedCVEDatabase 返回默认位置的 CVE 库，首次调用时从磁盘加载
+func loadSharedCVEDatabase() (*CVEDatabase, error) {
+	sharedCVEDBMutex.Lock()
+	defer sharedCVEDBMutex.Unlock()
+	if sharedCVEDB != nil {
+		return sharedCVEDB, nil
+	}
+
+	path, err := DefaultCVEDatabasePath()
+	if err != nil {
+		return nil, err
+	}
+	db, err := LoadCVEDatabase(path)
+	if err != nil {
+		return nil, err
+	}
+	sharedCVEDB = db
+	return db, nil
+}
+
+// ImportCVEFeeds 将 NVD 数据源导入默认位置的 CVE 库并保存
+func ImportCVEFeeds(paths ...string) (CVEDatabaseInfo, error) {
+	path, err := DefaultCVEDatabasePath()
+	if err != nil {
+		return CVEDatabaseInfo{}, err
+	}
+	// 在副本上导入，扫描中使用的库不受影响
+	db, err := LoadCVEDatabase(path)
+	if err != nil {
+		return CVEDatabaseInfo{}, err
+	}
+	for _, feed := range paths {
+		if _, err := db.ImportFeed(feed); err != nil {
+			return CVEDatabaseInfo{}, err
+		}
+	}
+	if err := db.Save(path); err != nil {
+		return CVEDatabaseInfo{}, err
+	}
+
+	sharedCVEDBMutex.Lock()
+	sharedCVEDB = db
+	sharedCVEDBMutex.Unlock()
+
+	info := db.Info()
+	info.Path = path
+	return info, nil
+}
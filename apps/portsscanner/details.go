@@ -0,0 +1,65 @@
+package portsscanner
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ServiceDetails 服务特有的元数据，值为 string、int、bool 或 []string
+type ServiceDetails map[string]any
+
+// String 按键名排序输出 "键: 值" 形式的多行文本
+func (d ServiceDetails) String() string {
+	keys := make([]string, 0, len(d))
+	for key := range d {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := d[key]
+		switch list := value.(type) {
+		case []string:
+			value = strings.Join(list, ",")
+		case []any:
+			// 前端传回的结果经 JSON 解码后列表为 []any
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			value = strings.Join(items, ",")
+		}
+		lines = append(lines, fmt.Sprintf("%s: %v", key, value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// detailExtractor 连接已识别的服务提取元数据，所有请求均为只读且不携带凭据，
+// 不是目标服务或无法提取时返回错误
+type detailExtractor func(ctx context.Context, portInfo PortInfo, timeout time.Duration) (ServiceDetails, error)
+
+// detailExtractors 按 gonmap 识别出的服务名索引
+var detailExtractors = map[string]detailExtractor{}
+
+func registerDetailExtractor(extract detailExtractor, names ...string) {
+	for _, name := range names {
+		detailExtractors[name] = extract
+	}
+}
+
+// extractDetails 对端口执行适用的元数据提取，没有适用的提取器或提取失败时返回 nil
+func extractDetails(ctx context.Context, portInfo PortInfo, timeout time.Duration) ServiceDetails {
+	extract, ok := detailExtractors[strings.ToLower(portInfo.Service)]
+	if !ok || ctx.Err() != nil {
+		return nil
+	}
+	details, err := extract(ctx, portInfo, timeout)
+	if err != nil || len(details) == 0 {
+		return nil
+	}
+	return details
+}
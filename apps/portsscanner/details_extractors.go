@@ -0,0 +1,613 @@
+package portsscanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func init() {
+	registerDetailExtractor(extractSSHDetails, "ssh")
+	registerDetailExtractor(extractSMBDetails, "microsoft-ds", "netbios-ssn", "smb")
+	registerDetailExtractor(extractRDPDetails, "ms-wbt-server", "rdp")
+	registerDetailExtractor(extractMySQLDetails, "mysql")
+	registerDetailExtractor(extractRedisDetails, "redis")
+}
+
+// SSH 报文长度上限，KEXINIT 通常不超过数 KB
+const maxSSHPacketSize = 35000
+
+const sshMsgKexInit = 20
+
+// errHostKeyCaptured 取得主机公钥后中止握手，不进入认证阶段
+var errHostKeyCaptured = errors.New("已取得主机公钥")
+
+// extractSSHDetails 读取服务端标识与 KEXINIT 中的算法列表，再通过一次密钥交换取得主机公钥指纹
+func extractSSHDetails(ctx context.Context, portInfo PortInfo, timeout time.Duration) (ServiceDetails, error) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	// 服务端标识之前允许出现其他文本行
+	reader := bufio.NewReader(conn)
+	var banner string
+	for i := 0; i < 16 && banner == ""; i++ {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(line, "SSH-") {
+			banner = strings.TrimRight(line, "\r\n")
+		}
+	}
+	if banner == "" {
+		return nil, fmt.Errorf("未收到 SSH 标识")
+	}
+	if _, err := conn.Write([]byte("SSH-2.0-OpenSSH_9.6\r\n")); err != nil {
+		return nil, err
+	}
+
+	payload, err := readSSHPacket(reader)
+	if err != nil {
+		return nil, err
+	}
+	// 消息类型与 16 字节 cookie 之后依次为 10 个算法列表
+	if len(payload) < 17 || payload[0] != sshMsgKexInit {
+		return nil, fmt.Errorf("未收到 KEXINIT")
+	}
+	lists, ok := parseNameLists(payload[17:], 10)
+	if !ok {
+		return nil, fmt.Errorf("KEXINIT 格式错误")
+	}
+
+	details := ServiceDetails{
+		"banner":              banner,
+		"kex_algorithms":      lists[0],
+		"host_key_algorithms": lists[1],
+		"ciphers":             lists[2],
+		"macs":                lists[4],
+		"compression":         lists[6],
+	}
+	if key, err := sshHostKey(ctx, portInfo, timeout); err == nil {
+		details["host_key_type"] = key.Type()
+		details["host_key_fingerprint"] = ssh.FingerprintSHA256(key)
+	}
+	return details, nil
+}
+
+// readSSHPacket 读取一个未加密的 SSH 二进制报文并返回载荷
+func readSSHPacket(reader io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+	length := int(binary.BigEndian.Uint32(header[:4]))
+	padding := int(header[4])
+	if length > maxSSHPacketSize || padding+1 > length {
+		return nil, fmt.Errorf("SSH 报文长度无效: %d", length)
+	}
+	packet := make([]byte, length-1)
+	if _, err := io.ReadFull(reader, packet); err != nil {
+		return nil, err
+	}
+	return packet[:length-1-padding], nil
+}
+
+// parseNameLists 解析连续的 SSH name-list 字段
+func parseNameLists(data []byte, count int) ([][]string, bool) {
+	lists := make([][]string, 0, count)
+	for i := 0; i < count; i++ {
+		if len(data) < 4 {
+			return nil, false
+		}
+		n := int(binary.BigEndian.Uint32(data))
+		if len(data) < 4+n {
+			return nil, false
+		}
+		var names []string
+		if n > 0 {
+			names = strings.Split(string(data[4:4+n]), ",")
+		}
+		lists = append(lists, names)
+		data = data[4+n:]
+	}
+	return lists, true
+}
+
+// sshHostKey 完成密钥交换取得主机公钥，校验回调中返回错误使握手在认证前结束
+func sshHostKey(ctx context.Context, portInfo PortInfo, timeout time.Duration) (ssh.PublicKey, error) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	var hostKey ssh.PublicKey
+	config := &ssh.ClientConfig{
+		User: "root",
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			hostKey = key
+			return errHostKeyCaptured
+		},
+		Timeout: timeout,
+	}
+	addr := net.JoinHostPort(portInfo.Host, strconv.Itoa(portInfo.Port))
+	if _, _, _, err := ssh.NewClientConn(conn, addr, config); hostKey == nil {
+		return nil, err
+	}
+	return hostKey, nil
+}
+
+// SMB2 命令与状态
+const (
+	smb2Negotiate    = 0x0000
+	smb2SessionSetup = 0x0001
+
+	ntStatusMoreProcessingRequired = 0xC0000016
+)
+
+// 协商时提供的 SMB2 方言，3.1.1 需要额外的协商上下文，这里不提供
+var smb2Dialects = []uint16{0x0202, 0x0210, 0x0300, 0x0302}
+
+// NTLM 质询中 AV_PAIR 的类型与对应的键名
+var ntlmAVNames = map[uint16]string{
+	1: "netbios_computer",
+	2: "netbios_domain",
+	3: "dns_computer",
+	4: "dns_domain",
+	5: "dns_forest",
+}
+
+// extractSMBDetails 通过 SMB2 协商取得方言与签名要求，再发起匿名 NTLM 认证，
+// 从服务端质询中读取计算机名、域名与系统版本，不会完成认证
+func extractSMBDetails(ctx context.Context, portInfo PortInfo, timeout time.Duration) (ServiceDetails, error) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	// 139 端口需要先建立 NetBIOS 会话
+	if strings.EqualFold(portInfo.Service, "netbios-ssn") {
+		if err := netbiosSessionRequest(conn); err != nil {
+			return nil, err
+		}
+	}
+
+	body := make([]byte, 36, 36+2*len(smb2Dialects))
+	binary.LittleEndian.PutUint16(body[0:], 36)
+	binary.LittleEndian.PutUint16(body[2:], uint16(len(smb2Dialects)))
+	binary.LittleEndian.PutUint16(body[4:], 1) // 支持签名
+	for _, dialect := range smb2Dialects {
+		body = binary.LittleEndian.AppendUint16(body, dialect)
+	}
+	status, resp, err := smb2RoundTrip(conn, smb2Negotiate, 0, body)
+	if err != nil {
+		return nil, err
+	}
+	if status != 0 || len(resp) < 8 {
+		return nil, fmt.Errorf("SMB2 协商失败: 0x%08X", status)
+	}
+	securityMode := binary.LittleEndian.Uint16(resp[2:])
+	dialect := binary.LittleEndian.Uint16(resp[4:])
+	details := ServiceDetails{
+		"dialect":          smb2DialectName(dialect),
+		"signing_enabled":  securityMode&0x01 != 0,
+		"signing_required": securityMode&0x02 != 0,
+	}
+
+	// 会话建立请求: 安全缓冲区紧跟在 24 字节的固定部分之后
+	token := spnegoNTLMNegotiate()
+	setup := make([]byte, 24, 24+len(token))
+	binary.LittleEndian.PutUint16(setup[0:], 25)
+	setup[3] = 1
+	binary.LittleEndian.PutUint16(setup[12:], 64+24)
+	binary.LittleEndian.PutUint16(setup[14:], uint16(len(token)))
+	setup = append(setup, token...)
+	status, resp, err = smb2RoundTrip(conn, smb2SessionSetup, 1, setup)
+	if err != nil || status != ntStatusMoreProcessingRequired || len(resp) < 8 {
+		return details, nil
+	}
+	// 响应中的偏移从 SMB2 头部开始计算
+	offset := int(binary.LittleEndian.Uint16(resp[4:])) - 64
+	length := int(binary.LittleEndian.Uint16(resp[6:]))
+	if offset < 0 || offset+length > len(resp) {
+		return details, nil
+	}
+	buffer := resp[offset : offset+length]
+	if i := bytes.Index(buffer, []byte("NTLMSSP\x00")); i >= 0 {
+		parseNTLMChallenge(buffer[i:], details)
+	}
+	return details, nil
+}
+
+func smb2DialectName(dialect uint16) string {
+	switch dialect {
+	case 0x0202:
+		return "2.0.2"
+	case 0x0210:
+		return "2.1"
+	case 0x0300:
+		return "3.0"
+	case 0x0302:
+		return "3.0.2"
+	case 0x0311:
+		return "3.1.1"
+	}
+	return fmt.Sprintf("0x%04X", dialect)
+}
+
+// netbiosSessionRequest 以 *SMBSERVER 为被叫名称建立 NetBIOS 会话
+func netbiosSessionRequest(conn net.Conn) error {
+	payload := append(netbiosName("*SMBSERVER"), netbiosName("GLIDEWAY")...)
+	req := append([]byte{0x81, 0, 0, byte(len(payload))}, payload...)
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	var resp [4]byte
+	if _, err := io.ReadFull(conn, resp[:]); err != nil {
+		return err
+	}
+	if resp[0] != 0x82 {
+		return fmt.Errorf("NetBIOS 会话被拒绝: 0x%02X", resp[0])
+	}
+	return nil
+}
+
+// netbiosName 按 RFC 1001 的一级编码将名称填充为 16 字节后编码
+func netbiosName(name string) []byte {
+	padded := fmt.Sprintf("%-15s ", name)
+	encoded := []byte{32}
+	for i := 0; i < 16; i++ {
+		encoded = append(encoded, 'A'+padded[i]>>4, 'A'+padded[i]&0x0f)
+	}
+	return append(encoded, 0)
+}
+
+// smb2RoundTrip 发送一个 SMB2 请求，返回响应状态与 64 字节头部之后的内容
+func smb2RoundTrip(conn net.Conn, command uint16, messageID uint64, body []byte) (uint32, []byte, error) {
+	header := make([]byte, 64)
+	copy(header, "\xfeSMB")
+	binary.LittleEndian.PutUint16(header[4:], 64)
+	binary.LittleEndian.PutUint16(header[12:], command)
+	binary.LittleEndian.PutUint16(header[14:], 1) // 申请的信用数
+	binary.LittleEndian.PutUint64(header[24:], messageID)
+
+	size := len(header) + len(body)
+	packet := []byte{0, byte(size >> 16), byte(size >> 8), byte(size)}
+	packet = append(append(packet, header...), body...)
+	if _, err := conn.Write(packet); err != nil {
+		return 0, nil, err
+	}
+
+	var prefix [4]byte
+	if _, err := io.ReadFull(conn, prefix[:]); err != nil {
+		return 0, nil, err
+	}
+	size = int(prefix[1])<<16 | int(prefix[2])<<8 | int(prefix[3])
+	if size < 64 || size > 1<<16 {
+		return 0, nil, fmt.Errorf("SMB2 响应长度无效: %d", size)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return 0, nil, err
+	}
+	if !bytes.HasPrefix(resp, []byte("\xfeSMB")) {
+		return 0, nil, fmt.Errorf("不是 SMB2 响应")
+	}
+	return binary.LittleEndian.Uint32(resp[8:]), resp[64:], nil
+}
+
+// spnegoNTLMNegotiate 构造包含 NTLM NEGOTIATE 消息的 SPNEGO NegTokenInit
+func spnegoNTLMNegotiate() []byte {
+	// 签名、消息类型、协商标志(Unicode、请求目标信息与版本、扩展会话安全等)，域与工作站为空
+	negotiate := make([]byte, 32)
+	copy(negotiate, "NTLMSSP\x00")
+	binary.LittleEndian.PutUint32(negotiate[8:], 1)
+	binary.LittleEndian.PutUint32(negotiate[12:], 0xa2888205)
+
+	spnegoOID := []byte{0x06, 0x06, 0x2b, 0x06, 0x01, 0x05, 0x05, 0x02}
+	ntlmOID := []byte{0x06, 0x0a, 0x2b, 0x06, 0x01, 0x04, 0x01, 0x82, 0x37, 0x02, 0x02, 0x0a}
+	return derTLV(0x60, spnegoOID,
+		derTLV(0xa0,
+			derTLV(0x30,
+				derTLV(0xa0, derTLV(0x30, ntlmOID)),
+				derTLV(0xa2, derTLV(0x04, negotiate)))))
+}
+
+// derTLV 按 DER 编码拼接标签、长度与内容
+func derTLV(tag byte, content ...[]byte) []byte {
+	body := bytes.Join(content, nil)
+	n := len(body)
+	var length []byte
+	switch {
+	case n < 0x80:
+		length = []byte{byte(n)}
+	case n < 0x100:
+		length = []byte{0x81, byte(n)}
+	default:
+		length = []byte{0x82, byte(n >> 8), byte(n)}
+	}
+	return append(append([]byte{tag}, length...), body...)
+}
+
+// parseNTLMChallenge 从 NTLM CHALLENGE 消息的目标信息与版本字段中提取主机信息
+func parseNTLMChallenge(msg []byte, details ServiceDetails) {
+	if len(msg) < 48 || binary.LittleEndian.Uint32(msg[8:]) != 2 {
+		return
+	}
+	flags := binary.LittleEndian.Uint32(msg[20:])
+	length := int(binary.LittleEndian.Uint16(msg[40:]))
+	offset := int(binary.LittleEndian.Uint32(msg[44:]))
+	if offset+length <= len(msg) {
+		info := msg[offset : offset+length]
+		for len(info) >= 4 {
+			id := binary.LittleEndian.Uint16(info)
+			n := int(binary.LittleEndian.Uint16(info[2:]))
+			if id == 0 || len(info) < 4+n {
+				break
+			}
+			if name, ok := ntlmAVNames[id]; ok {
+				details[name] = decodeUTF16(info[4 : 4+n])
+			}
+			info = info[4+n:]
+		}
+	}
+	// 协商了版本信息时，第 48 字节起为主版本、次版本与内部版本号
+	if flags&0x02000000 != 0 && len(msg) >= 56 && msg[48] != 0 {
+		details["os_version"] = fmt.Sprintf("%d.%d build %d", msg[48], msg[49], binary.LittleEndian.Uint16(msg[50:]))
+	}
+}
+
+func decodeUTF16(data []byte) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// RDP 安全协议，见 MS-RDPBCGR 2.2.1.1.1
+const (
+	rdpProtocolRDP      = 0x00
+	rdpProtocolSSL      = 0x01
+	rdpProtocolHybrid   = 0x02
+	rdpProtocolRDSTLS   = 0x04
+	rdpProtocolHybridEx = 0x08
+)
+
+// RDP 协商响应类型与失败码
+const (
+	rdpNegResponse = 0x02
+	rdpNegFailure  = 0x03
+
+	rdpHybridRequiredByServer = 0x05
+)
+
+// extractRDPDetails 通过 X.224 连接请求中的安全协议协商判断是否强制 NLA(CredSSP)，
+// 协商出 TLS 时读取证书的 CN
+func extractRDPDetails(ctx context.Context, portInfo PortInfo, timeout time.Duration) (ServiceDetails, error) {
+	conn, typ, value, err := rdpNegotiate(ctx, portInfo, timeout, rdpProtocolSSL|rdpProtocolHybrid|rdpProtocolHybridEx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	// 协商失败或早期版本不支持协商时只能使用标准 RDP 安全层
+	selected := uint32(rdpProtocolRDP)
+	if typ == rdpNegResponse {
+		selected = value
+	}
+	nlaSupported := selected&(rdpProtocolHybrid|rdpProtocolHybridEx) != 0
+	details := ServiceDetails{
+		"selected_protocol": rdpProtocolName(selected),
+		"nla_supported":     nlaSupported,
+		"nla_required":      false,
+	}
+
+	if selected != rdpProtocolRDP {
+		tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS10})
+		if err := tlsConn.Handshake(); err == nil {
+			if certs := tlsConn.ConnectionState().PeerCertificates; len(certs) > 0 {
+				details["certificate_cn"] = certs[0].Subject.CommonName
+				details["certificate_not_after"] = certs[0].NotAfter.Format(time.DateOnly)
+			}
+		}
+	}
+
+	// 只请求 TLS 时服务端返回 HYBRID_REQUIRED_BY_SERVER 说明强制 NLA
+	if nlaSupported {
+		if conn, typ, value, err := rdpNegotiate(ctx, portInfo, timeout, rdpProtocolSSL); err == nil {
+			conn.Close()
+			details["nla_required"] = typ == rdpNegFailure && value == rdpHybridRequiredByServer
+		}
+	}
+	return details, nil
+}
+
+// rdpNegotiate 发送携带 RDP_NEG_REQ 的 X.224 连接请求，返回连接与协商响应的类型及值，
+// 服务端未返回协商数据时类型为 0
+func rdpNegotiate(ctx context.Context, portInfo PortInfo, timeout time.Duration, protocols uint32) (net.Conn, byte, uint32, error) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	// TPKT 头部、X.224 CR TPDU 与 RDP_NEG_REQ
+	req := []byte{
+		0x03, 0x00, 0x00, 0x13,
+		0x0e, 0xe0, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x08, 0x00, 0, 0, 0, 0,
+	}
+	binary.LittleEndian.PutUint32(req[15:], protocols)
+	if _, err := conn.Write(req); err != nil {
+		conn.Close()
+		return nil, 0, 0, err
+	}
+
+	var tpkt [4]byte
+	if _, err := io.ReadFull(conn, tpkt[:]); err != nil || tpkt[0] != 0x03 {
+		conn.Close()
+		return nil, 0, 0, fmt.Errorf("不是 RDP 服务")
+	}
+	size := int(binary.BigEndian.Uint16(tpkt[2:]))
+	if size < 11 || size > maxBannerSize {
+		conn.Close()
+		return nil, 0, 0, fmt.Errorf("TPKT 长度无效: %d", size)
+	}
+	resp := make([]byte, size-4)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		conn.Close()
+		return nil, 0, 0, err
+	}
+	// X.224 CC TPDU 固定 7 字节，之后为 8 字节的协商响应
+	if resp[1]&0xf0 != 0xd0 {
+		conn.Close()
+		return nil, 0, 0, fmt.Errorf("不是 X.224 连接确认")
+	}
+	if len(resp) < 15 {
+		return conn, 0, 0, nil
+	}
+	return conn, resp[7], binary.LittleEndian.Uint32(resp[11:]), nil
+}
+
+func rdpProtocolName(protocol uint32) string {
+	switch {
+	case protocol&rdpProtocolHybridEx != 0:
+		return "CredSSP (Early User Auth)"
+	case protocol&rdpProtocolHybrid != 0:
+		return "CredSSP"
+	case protocol&rdpProtocolRDSTLS != 0:
+		return "RDSTLS"
+	case protocol&rdpProtocolSSL != 0:
+		return "TLS"
+	}
+	return "RDP"
+}
+
+// MySQL 能力标志
+const (
+	mysqlClientSSL        = 0x00000800
+	mysqlClientPluginAuth = 0x00080000
+)
+
+// extractMySQLDetails 解析服务端的初始握手包，拒绝连接时记录错误码与信息
+func extractMySQLDetails(ctx context.Context, portInfo PortInfo, timeout time.Duration) (ServiceDetails, error) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	var header [4]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return nil, err
+	}
+	size := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if size == 0 || size > maxBannerSize {
+		return nil, fmt.Errorf("MySQL 握手包长度无效: %d", size)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return nil, err
+	}
+
+	// 错误包: 0xff、错误码与信息，如 "Host ... is not allowed to connect"
+	if payload[0] == 0xff && len(payload) >= 3 {
+		return ServiceDetails{
+			"error_code": int(binary.LittleEndian.Uint16(payload[1:])),
+			"error":      string(payload[3:]),
+		}, nil
+	}
+	if payload[0] != 10 {
+		return nil, fmt.Errorf("不支持的握手协议版本: %d", payload[0])
+	}
+
+	end := bytes.IndexByte(payload[1:], 0)
+	if end < 0 || len(payload) < 1+end+1+4+8+1+2 {
+		return nil, fmt.Errorf("MySQL 握手包格式错误")
+	}
+	details := ServiceDetails{
+		"protocol_version": int(payload[0]),
+		"server_version":   string(payload[1 : 1+end]),
+	}
+	pos := 1 + end + 1
+	details["connection_id"] = int(binary.LittleEndian.Uint32(payload[pos:]))
+	pos += 4 + 8 + 1 // 连接 ID、认证数据前 8 字节与填充
+	capabilities := uint32(binary.LittleEndian.Uint16(payload[pos:]))
+	pos += 2
+
+	// 字符集、状态、能力标志高 16 位、认证数据长度与 10 字节保留
+	if len(payload) >= pos+16 {
+		capabilities |= uint32(binary.LittleEndian.Uint16(payload[pos+3:])) << 16
+		authLen := int(payload[pos+5])
+		pos += 16 + max(13, authLen-8)
+		if capabilities&mysqlClientPluginAuth != 0 && pos < len(payload) {
+			plugin := payload[pos:]
+			if i := bytes.IndexByte(plugin, 0); i >= 0 {
+				plugin = plugin[:i]
+			}
+			details["auth_plugin"] = string(plugin)
+		}
+	}
+	details["ssl"] = capabilities&mysqlClientSSL != 0
+	return details, nil
+}
+
+// Redis INFO 中提取的字段
+var redisInfoKeys = []string{
+	"redis_version", "redis_mode", "os", "arch_bits", "process_id", "tcp_port", "uptime_in_days",
+	"executable", "config_file", "connected_clients", "used_memory_human", "maxmemory_human",
+	"role", "master_host", "master_port", "connected_slaves", "aof_enabled",
+}
+
+// extractRedisDetails 未设置口令时读取 INFO 中的版本、运行环境、复制角色与各库键数量
+func extractRedisDetails(ctx context.Context, portInfo PortInfo, timeout time.Duration) (ServiceDetails, error) {
+	body, err := redisInfo(ctx, portInfo, timeout)
+	if errors.Is(err, errRedisAuth) {
+		return ServiceDetails{"auth_required": true}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	details := ServiceDetails{"auth_required": false}
+	for _, field := range infoFields(body, ":", redisInfoKeys...) {
+		key, value, _ := strings.Cut(field, ":")
+		if n, err := strconv.Atoi(value); err == nil {
+			details[key] = n
+		} else {
+			details[key] = value
+		}
+	}
+	// Keyspace 段每个库一行，如 db0:keys=1,expires=0,avg_ttl=0
+	var keyspace []string
+	for _, line := range strings.Split(body, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "db") && strings.Contains(line, ":keys=") {
+			keyspace = append(keyspace, line)
+		}
+	}
+	if len(keyspace) > 0 {
+		details["keyspace"] = keyspace
+	}
+	return details, nil
+}
@@ -0,0 +1,524 @@
+package portsscanner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// 主机存活探测方式
+const (
+	DiscoveryICMP = "icmp"
+	DiscoveryTCP  = "tcp"
+	// 不主动发送 ARP 请求，只读取内核 ARP / NDP 邻居表，表项由前面的 ICMP/TCP 探测触发解析
+	DiscoveryNeighbor = "neighbor"
+)
+
+// 主机发现使用的 TCP 端口，SYN 发往 443、ACK 发往 80 与 nmap 默认一致
+var (
+	tcpPingSYNPorts     = []int{443}
+	tcpPingACKPorts     = []int{80}
+	tcpPingConnectPorts = []int{80, 443, 22, 445, 3389}
+)
+
+// HostInfo 存活主机信息
+type HostInfo struct {
+	Host   string  `json:"host"`
+	Domain string  `json:"domain,omitempty"`
+	Method string  `json:"method"`        // 判定存活的方式: icmp / tcp / neighbor
+	RTT    float64 `json:"rtt"`           // 往返时延(毫秒)，邻居表判定时为 0
+	MAC    string  `json:"mac,omitempty"` // 同一网段时从邻居表获取
+}
+
+type HostCallback func(HostInfo)
+
+// DiscoverHosts 对目标进行存活探测，返回存活的主机并对每个存活主机调用 callback。
+// 同时进行 ICMP / ICMPv6 Echo(有权限时)与 TCP SYN/ACK Ping(无原始套接字权限或 IPv6 时退化为 TCP 连接)，
+// 最后对本地网段的主机读取内核 ARP / NDP 邻居表(不主动发送 ARP 请求)
+func DiscoverHosts(ctx context.Context, targets []Target, maxThreads int, timeout time.Duration, callback HostCallback) ([]Target, error) {
+	targets, err := ExpandLinkTargets(ctx, targets)
+	if err != nil {
+		return nil, err
+	}
+
+	pinger, _ := newICMPPinger()
+	if pinger != nil {
+		defer pinger.Close()
+	}
+	syn, _ := newSYNScanner()
+	if syn != nil {
+		defer syn.Close()
+	}
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		alive     = make([]bool, len(targets))
+		rtts      = make([]time.Duration, len(targets))
+		semaphore = make(chan struct{}, maxThreads)
+	)
+
+	for i, target := range targets {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return nil, context.Canceled
+		default:
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(i int, target Target) {
+			defer func() {
+				wg.Done()
+				<-semaphore
+			}()
+
+			info, ok := probeHost(ctx, target, pinger, syn, timeout)
+			if !ok {
+				return
+			}
+
+			mu.Lock()
+			alive[i] = true
+			rtts[i] = time.Duration(info.RTT * float64(time.Millisecond))
+			mu.Unlock()
+			if callback != nil {
+				callback(info)
+			}
+		}(i, target)
+	}
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, context.Canceled
+	}
+
+	var result []Target
+	for i, target := range targets {
+		if alive[i] {
+			target.RTT = rtts[i]
+			result = append(result, target)
+		}
+	}
+	return result, nil
+}
+
+func probeHost(ctx context.Context, target Target, pinger *icmpPinger, syn *synScanner, timeout time.Duration) (HostInfo, bool) {
+	info := HostInfo{Host: target.IP, Domain: target.Domain}
+	ip := net.ParseIP(target.IP)
+
+	// ICMP 与 TCP 探测并发进行，以最先得到的响应为准
+	probeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	start := time.Now()
+	methods := make(chan string, 2)
+	go func() {
+		if pinger != nil && pinger.Ping(probeCtx, ip, timeout) {
+			methods <- DiscoveryICMP
+			return
+		}
+		methods <- ""
+	}()
+	go func() {
+		if tcpPing(probeCtx, ip, syn, timeout) {
+			methods <- DiscoveryTCP
+			return
+		}
+		methods <- ""
+	}()
+
+	for i := 0; i < 2 && info.Method == ""; i++ {
+		info.Method = <-methods
+	}
+	if info.Method != "" {
+		info.RTT = float64(time.Since(start).Microseconds()) / 1000
+	}
+
+	// 前面的探测已触发 ARP / 邻居发现，同网段主机即使过滤了 ICMP/TCP 也能在邻居表中找到
+	if isLocalSegment(ip) {
+		if mac := neighborLookup(ip); mac != "" {
+			info.MAC = mac
+			if info.Method == "" {
+				info.Method = DiscoveryNeighbor
+			}
+		}
+	}
+
+	return info, info.Method != ""
+}
+
+// tcpPing 任何 TCP 响应(SYN/ACK、RST 或连接被拒绝)都说明主机存活
+func tcpPing(ctx context.Context, ip net.IP, syn *synScanner, timeout time.Duration) bool {
+	if syn != nil && ip.To4() != nil {
+		results := make(chan bool, len(tcpPingSYNPorts)+len(tcpPingACKPorts))
+		send := func(port int, flags byte) {
+			reply, err := syn.probeFlags(ctx, ip.To4(), port, flags, timeout)
+			results <- err == nil && reply != 0
+		}
+		for _, port := range tcpPingSYNPorts {
+			go send(port, tcpFlagSYN)
+		}
+		for _, port := range tcpPingACKPorts {
+			go send(port, tcpFlagACK)
+		}
+		for i := 0; i < cap(results); i++ {
+			if <-results {
+				return true
+			}
+		}
+		return false
+	}
+
+	results := make(chan bool, len(tcpPingConnectPorts))
+	for _, port := range tcpPingConnectPorts {
+		go func(port int) {
+			conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip.String(), strconv.Itoa(port)), timeout)
+			if err == nil {
+				conn.Close()
+				results <- true
+				return
+			}
+			results <- isPortUnreachable(err)
+		}(port)
+	}
+	for range tcpPingConnectPorts {
+		if <-results {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocalSegment 判断地址是否位于本机某个网卡直连的网段
+func isLocalSegment(ip net.IP) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// icmpPinger 每个协议族共享一个 ICMP 套接字发送 Echo 请求，按序号分发 Echo 响应
+type icmpPinger struct {
+	conn4, conn6             *icmp.PacketConn
+	privileged4, privileged6 bool
+	id                       int
+
+	mu      sync.Mutex
+	seq     int
+	waiters map[int]*pingWaiter
+}
+
+// pingWaiter 等待指定地址对某个序号的 Echo 响应
+type pingWaiter struct {
+	ip string
+	ch chan struct{}
+}
+
+// newICMPPinger 分别打开 ICMP 与 ICMPv6 套接字，任一协议族可用即可
+func newICMPPinger() (*icmpPinger, error) {
+	p := &icmpPinger{
+		id:      os.Getpid() & 0xffff,
+		waiters: make(map[int]*pingWaiter),
+	}
+
+	var err4, err6 error
+	p.conn4, p.privileged4, err4 = listenICMP("udp4", "ip4:icmp", "0.0.0.0")
+	p.conn6, p.privileged6, err6 = listenICMP("udp6", "ip6:ipv6-icmp", "::")
+	if p.conn4 == nil && p.conn6 == nil {
+		return nil, errors.Join(err4, err6)
+	}
+
+	if p.conn4 != nil {
+		go p.receiveLoop(p.conn4, p.privileged4, protocolICMP, ipv4.ICMPTypeEchoReply)
+	}
+	if p.conn6 != nil {
+		go p.receiveLoop(p.conn6, p.privileged6, protocolICMPv6, ipv6.ICMPTypeEchoReply)
+	}
+	return p, nil
+}
+
+// listenICMP 优先使用无需特权的 ICMP 套接字，失败时尝试原始套接字，返回值表示是否为原始套接字
+func listenICMP(network, rawNetwork, address string) (*icmp.PacketConn, bool, error) {
+	conn, err := icmp.ListenPacket(network, address)
+	if err == nil {
+		return conn, false, nil
+	}
+	if conn, err = icmp.ListenPacket(rawNetwork, address); err != nil {
+		return nil, false, err
+	}
+	return conn, true, nil
+}
+
+// icmpDst 按套接字类型构造目的地址，zone 为 IPv6 链路本地或组播地址的出口网卡
+func icmpDst(ip net.IP, zone string, privileged bool) net.Addr {
+	if privileged {
+		return &net.IPAddr{IP: ip, Zone: zone}
+	}
+	return &net.UDPAddr{IP: ip, Zone: zone}
+}
+
+// ICMP 报文解析时使用的协议号
+const (
+	protocolICMP   = 1
+	protocolICMPv6 = 58
+)
+
+func (p *icmpPinger) Close() {
+	if p.conn4 != nil {
+		p.conn4.Close()
+	}
+	if p.conn6 != nil {
+		p.conn6.Close()
+	}
+}
+
+// Ping 发送 ICMP / ICMPv6 Echo 请求并等待响应
+func (p *icmpPinger) Ping(ctx context.Context, ip net.IP, timeout time.Duration) bool {
+	conn, privileged := p.conn4, p.privileged4
+	var typ icmp.Type = ipv4.ICMPTypeEcho
+	if ip.To4() == nil {
+		conn, privileged = p.conn6, p.privileged6
+		typ = ipv6.ICMPTypeEchoRequest
+	}
+	if conn == nil {
+		return false
+	}
+	waiter := &pingWaiter{ip: normalizeIP(ip).String(), ch: make(chan struct{}, 1)}
+	p.mu.Lock()
+	p.seq++
+	seq := p.seq & 0xffff
+	p.waiters[seq] = waiter
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.waiters, seq)
+		p.mu.Unlock()
+	}()
+
+	msg := icmp.Message{
+		Type: typ,
+		Body: &icmp.Echo{ID: p.id, Seq: seq, Data: []byte("GlideWay")},
+	}
+	data, err := msg.Marshal(nil)
+	if err != nil {
+		return false
+	}
+
+	if _, err := conn.WriteTo(data, icmpDst(ip, "", privileged)); err != nil {
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-waiter.ch:
+		return true
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+	return false
+}
+
+// receiveLoop 只接受序号与来源地址都对应的响应。原始套接字会收到本机所有 ICMP 报文，还需校验标识符；
+// 非特权的 ICMP 套接字由内核改写并过滤标识符，无需校验
+func (p *icmpPinger) receiveLoop(conn *icmp.PacketConn, privileged bool, proto int, replyType icmp.Type) {
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		msg, err := icmp.ParseMessage(proto, buf[:n])
+		if err != nil || msg.Type != replyType {
+			continue
+		}
+		echo, ok := msg.Body.(*icmp.Echo)
+		if !ok || (privileged && echo.ID != p.id) {
+			continue
+		}
+
+		p.mu.Lock()
+		waiter, ok := p.waiters[echo.Seq]
+		p.mu.Unlock()
+		if ok && waiter.ip == normalizeIP(peerIP(peer)).String() {
+			select {
+			case waiter.ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// peerIP 取 ICMP 套接字返回的来源地址
+func peerIP(peer net.Addr) net.IP {
+	switch addr := peer.(type) {
+	case *net.UDPAddr:
+		return addr.IP
+	case *net.IPAddr:
+		return addr.IP
+	}
+	return nil
+}
+
+// ExpandLinkTargets 将目标中的 IPv6 链路网段替换为在链路上发现的主机，与其他目标重复的地址只保留一个
+func ExpandLinkTargets(ctx context.Context, targets []Target) ([]Target, error) {
+	if !hasLinkTargets(targets) {
+		return targets, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range targets {
+		seen[t.IP] = true
+	}
+	result := make([]Target, 0, len(targets))
+	for _, t := range targets {
+		if t.Network == "" {
+			result = append(result, t)
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(t.Network)
+		if err != nil {
+			return nil, fmt.Errorf("无效的网段: %s", t.Network)
+		}
+		hosts, err := discoverLinkHosts(ctx, ipNet)
+		if err != nil {
+			return nil, err
+		}
+		for _, host := range hosts {
+			if !seen[host.IP] {
+				seen[host.IP] = true
+				result = append(result, host)
+			}
+		}
+	}
+	return result, nil
+}
+
+func hasLinkTargets(targets []Target) bool {
+	for _, t := range targets {
+		if t.Network != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// 链路上发现 IPv6 主机时等待 ICMPv6 组播响应的时间
+const linkDiscoveryWait = 2 * time.Second
+
+// discoverLinkHosts 在本机直连的 IPv6 链路上发现网段内的主机:
+// 从本机在该网段的地址向 ff02::1 发送 ICMPv6 Echo，收集响应的地址，再合并内核 NDP 邻居表中属于该网段的地址
+func discoverLinkHosts(ctx context.Context, ipNet *net.IPNet) ([]Target, error) {
+	ifName, src := linkInterface(ipNet)
+	if src == nil {
+		return nil, fmt.Errorf("仅支持本机直连链路上的网段或 /112 以内的网段")
+	}
+
+	found := make(map[string]bool)
+	if err := multicastEcho(ctx, ifName, src, ipNet, found); err != nil && ctx.Err() != nil {
+		return nil, context.Canceled
+	}
+	// 无 ICMP 权限时仍可使用邻居表，组播探测也会让内核补全邻居表
+	for addr := range ipv6Neighbors() {
+		if ip := net.ParseIP(addr); ip != nil && ipNet.Contains(ip) {
+			found[ip.String()] = true
+		}
+	}
+
+	targets := make([]Target, 0, len(found))
+	for addr := range found {
+		targets = append(targets, Target{IP: addr})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return compareIP(net.ParseIP(targets[i].IP), net.ParseIP(targets[j].IP)) < 0
+	})
+	return targets, nil
+}
+
+// linkInterface 查找地址位于该网段的本机网卡，返回网卡名与本机地址
+func linkInterface(ipNet *net.IPNet) (string, net.IP) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return "", nil
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			if a, ok := addr.(*net.IPNet); ok && a.IP.To4() == nil && ipNet.Contains(a.IP) {
+				return iface.Name, a.IP
+			}
+		}
+	}
+	return "", nil
+}
+
+// multicastEcho 向链路上所有节点组播 ICMPv6 Echo，响应来源位于网段内的地址记入 found
+func multicastEcho(ctx context.Context, ifName string, src net.IP, ipNet *net.IPNet, found map[string]bool) error {
+	zone := ""
+	if src.IsLinkLocalUnicast() {
+		zone = "%" + ifName
+	}
+	conn, privileged, err := listenICMP("udp6", "ip6:ipv6-icmp", src.String()+zone)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	msg := icmp.Message{
+		Type: ipv6.ICMPTypeEchoRequest,
+		Body: &icmp.Echo{ID: os.Getpid() & 0xffff, Seq: 1, Data: []byte("GlideWay")},
+	}
+	data, err := msg.Marshal(nil)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.WriteTo(data, icmpDst(net.IPv6linklocalallnodes, ifName, privileged)); err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	conn.SetReadDeadline(time.Now().Add(linkDiscoveryWait))
+
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			// 等待超时即结束收集
+			return ctx.Err()
+		}
+		reply, err := icmp.ParseMessage(protocolICMPv6, buf[:n])
+		if err != nil || reply.Type != ipv6.ICMPTypeEchoReply {
+			continue
+		}
+		if ip := peerIP(peer); ip != nil && ipNet.Contains(ip) {
+			found[ip.String()] = true
+		}
+	}
+}
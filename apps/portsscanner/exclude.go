@@ -0,0 +1,153 @@
+package portsscanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ExcludeList 扫描排除列表，命中的主机或端口在探测前跳过
+type ExcludeList struct {
+	ranges []ipRange // 排除的地址范围，单个地址的起止相同
+	ports  map[int]bool
+}
+
+type ipRange struct {
+	start, end net.IP
+}
+
+// ParseExcludeList 解析排除的主机与端口。
+// hosts 支持 IP、CIDR、范围(10.0.0.1-50 / 10.0.0.1-10.0.0.50 / 2001:db8::10-1f)与域名，逗号或空白分隔，CIDR 与范围不展开，
+// 因此可以排除任意大小的网段；ports 为端口表达式，见 ParsePortSpec
+func ParseExcludeList(ctx context.Context, hosts, ports string) (*ExcludeList, error) {
+	e := &ExcludeList{ports: make(map[int]bool)}
+
+	for _, item := range splitTargetExpr(hosts) {
+		ranges, err := parseExcludeHost(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		e.ranges = append(e.ranges, ranges...)
+	}
+
+	if strings.TrimSpace(ports) != "" {
+		list, err := ParsePortSpec(ports)
+		if err != nil {
+			return nil, fmt.Errorf("排除端口: %w", err)
+		}
+		for _, p := range list {
+			e.ports[p] = true
+		}
+	}
+	return e, nil
+}
+
+func parseExcludeHost(ctx context.Context, item string) ([]ipRange, error) {
+	if strings.Contains(item, "/") {
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, fmt.Errorf("无效的排除网段: %s", item)
+		}
+		start := normalizeIP(ipNet.IP)
+		end := append(net.IP(nil), start...)
+		for i := range end {
+			end[i] |= ^ipNet.Mask[i]
+		}
+		return []ipRange{{start, end}}, nil
+	}
+
+	if ip := net.ParseIP(item); ip != nil {
+		return []ipRange{{ip, ip}}, nil
+	}
+
+	if idx := strings.LastIndex(item, "-"); idx > 0 {
+		if start := net.ParseIP(item[:idx]); start != nil {
+			end := parseRangeEnd(start, item[idx+1:])
+			if end == nil || (start.To4() == nil) != (end.To4() == nil) || compareIP(start, end) > 0 {
+				return nil, fmt.Errorf("无效的排除范围: %s", item)
+			}
+			return []ipRange{{start, end}}, nil
+		}
+	}
+
+	// 域名排除其解析到的全部地址
+	targets, err := resolveHost(ctx, item, IPPreferDual)
+	if err != nil {
+		return nil, fmt.Errorf("排除主机: %w", err)
+	}
+	ranges := make([]ipRange, 0, len(targets))
+	for _, t := range targets {
+		ip := net.ParseIP(t.IP)
+		ranges = append(ranges, ipRange{ip, ip})
+	}
+	return ranges, nil
+}
+
+// empty 判断是否没有任何排除项
+func (e *ExcludeList) empty() bool {
+	return e == nil || (len(e.ranges) == 0 && len(e.ports) == 0)
+}
+
+// ExcludesHost 判断主机是否在排除范围内
+func (e *ExcludeList) ExcludesHost(host string) bool {
+	if e == nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, r := range e.ranges {
+		if (ip.To4() == nil) != (r.start.To4() == nil) {
+			continue
+		}
+		if compareIP(ip, r.start) >= 0 && compareIP(ip, r.end) <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ExcludesPort 判断端口是否被排除
+func (e *ExcludeList) ExcludesPort(port int) bool {
+	return e != nil && e.ports[port]
+}
+
+// Filter 去掉排除的主机与端口，返回剩余的目标、端口与跳过的主机:端口组合数
+func (e *ExcludeList) Filter(targets []Target, ports []int) ([]Target, []int, int) {
+	if e.empty() {
+		return targets, ports, 0
+	}
+
+	keptTargets := make([]Target, 0, len(targets))
+	for _, t := range targets {
+		if !e.ExcludesHost(t.IP) {
+			keptTargets = append(keptTargets, t)
+		}
+	}
+	keptPorts := make([]int, 0, len(ports))
+	for _, p := range ports {
+		if !e.ExcludesPort(p) {
+			keptPorts = append(keptPorts, p)
+		}
+	}
+
+	skipped := len(targets)*len(ports) - len(keptTargets)*len(keptPorts)
+	return keptTargets, keptPorts, skipped
+}
+
+// FilterImported 去掉导入结果中排除的主机与端口，返回剩余的端口与跳过的数量
+func (e *ExcludeList) FilterImported(ports []ImportedPort) ([]ImportedPort, int) {
+	if e.empty() {
+		return ports, 0
+	}
+
+	kept := make([]ImportedPort, 0, len(ports))
+	for _, p := range ports {
+		if !e.ExcludesHost(p.Host) && !e.ExcludesPort(p.Port) {
+			kept = append(kept, p)
+		}
+	}
+	return kept, len(ports) - len(kept)
+}
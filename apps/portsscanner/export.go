@@ -0,0 +1,242 @@
+package portsscanner
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 支持的导出格式，按文件扩展名选择
+const (
+	ExportJSON = "json"
+	ExportCSV  = "csv"
+	ExportHTML = "html"
+	ExportXML  = "xml" // nmap -oX 格式
+)
+
+// exportFormat 根据扩展名返回导出格式
+func exportFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return ExportJSON, nil
+	case ".csv":
+		return ExportCSV, nil
+	case ".html", ".htm":
+		return ExportHTML, nil
+	case ".xml":
+		return ExportXML, nil
+	}
+	return "", fmt.Errorf("不支持的导出格式: %s，请使用 .json/.csv/.html/.xml", filepath.Ext(path))
+}
+
+// ExportScanRecord 按扩展名将扫描结果导出为 JSON、CSV、HTML 报告或 nmap XML
+func ExportScanRecord(path string, record *ScanRecord) error {
+	format, err := exportFormat(path)
+	if err != nil {
+		return err
+	}
+
+	return writeExportFile(path, func(w io.Writer) error {
+		switch format {
+		case ExportCSV:
+			return writePortsCSV(w, record.Ports)
+		case ExportHTML:
+			return writeHTMLReport(w, record)
+		case ExportXML:
+			return writeNmapXML(w, record)
+		default:
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(record)
+		}
+	})
+}
+
+// writeExportFile 创建文件并写入，写入失败时删除不完整的文件
+func writeExportFile(path string, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建导出文件失败: %w", err)
+	}
+	err = write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("导出失败: %w", err)
+	}
+	return nil
+}
+
+// writeCSV 写入带 UTF-8 BOM 的 CSV，便于 Excel 正确识别中文
+func writeCSV(w io.Writer, header []string, rows [][]string) error {
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func writePortsCSV(w io.Writer, ports []PortInfo) error {
+	header := []string{
+		"host", "port", "protocol", "state", "service", "product", "version", "info",
+		"hostname", "os", "device_type", "tls", "cpe", "http_status", "http_title", "http_server",
+		"cert_subject", "cert_not_after", "cves", "findings", "details",
+	}
+
+	rows := make([][]string, 0, len(ports))
+	for _, p := range ports {
+		var httpStatus, httpTitle, httpServer, certSubject, certNotAfter string
+		if p.HTTP != nil {
+			httpStatus = strconv.Itoa(p.HTTP.StatusCode)
+			httpTitle, httpServer = p.HTTP.Title, p.HTTP.Server
+		}
+		if p.Certificate != nil {
+			certSubject = p.Certificate.Subject
+			certNotAfter = p.Certificate.NotAfter.Format(time.DateOnly)
+		}
+		var cves, findings []string
+		for _, cve := range p.CVEs {
+			cves = append(cves, fmt.Sprintf("%s(%.1f)", cve.ID, cve.CVSS))
+		}
+		for _, finding := range p.Findings {
+			findings = append(findings, finding.Name)
+		}
+
+		rows = append(rows, []string{
+			p.Host, strconv.Itoa(p.Port), p.Protocol, p.State, p.Service, p.ProductName, p.Version, p.Info,
+			p.Hostname, p.OperatingSystem, p.DeviceType, strconv.FormatBool(p.TLS), p.CPE, httpStatus, httpTitle, httpServer,
+			certSubject, certNotAfter, strings.Join(cves, ";"), strings.Join(findings, ";"),
+			strings.ReplaceAll(p.Details.String(), "\n", "; "),
+		})
+	}
+	return writeCSV(w, header, rows)
+}
+
+// reportHost HTML 报告中的单个主机
+type reportHost struct {
+	Host  string
+	Ports []PortInfo
+}
+
+func writeHTMLReport(w io.Writer, record *ScanRecord) error {
+	ports := append([]PortInfo(nil), record.Ports...)
+	sortPorts(ports)
+
+	var hosts []reportHost
+	var open, cves, findings int
+	for _, p := range ports {
+		if len(hosts) == 0 || hosts[len(hosts)-1].Host != p.Host {
+			hosts = append(hosts, reportHost{Host: p.Host})
+		}
+		hosts[len(hosts)-1].Ports = append(hosts[len(hosts)-1].Ports, p)
+		if p.State == StateOpen {
+			open++
+		}
+		cves += len(p.CVEs)
+		findings += len(p.Findings)
+	}
+
+	return reportTemplate.Execute(w, map[string]any{
+		"Record":    record,
+		"Hosts":     hosts,
+		"Open":      open,
+		"CVEs":      cves,
+		"Findings":  findings,
+		"Generated": time.Now(),
+	})
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"time": func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Format(time.DateTime)
+	},
+	"cvss":  func(score float64) string { return fmt.Sprintf("%.1f", score) },
+	"lower": strings.ToLower,
+}).Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>GlideWay 端口扫描报告</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", "Microsoft YaHei", sans-serif; margin: 32px; color: #222; background: #f6f7f9; }
+h1 { margin-bottom: 4px; }
+.meta { color: #666; margin-bottom: 24px; }
+.cards { display: flex; gap: 16px; margin-bottom: 24px; }
+.card { background: #fff; border-radius: 8px; padding: 12px 20px; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+.card b { display: block; font-size: 24px; }
+.host { background: #fff; border-radius: 8px; padding: 16px 20px; margin-bottom: 20px; box-shadow: 0 1px 3px rgba(0,0,0,.08); }
+table { width: 100%; border-collapse: collapse; font-size: 13px; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+th { background: #fafafa; }
+.state-open { color: #2e7d32; font-weight: 600; }
+.state-closed { color: #999; }
+.sev { display: inline-block; padding: 0 6px; border-radius: 4px; color: #fff; font-size: 12px; margin: 1px 0; }
+.sev-critical, .sev-high { background: #d32f2f; }
+.sev-medium { background: #f57c00; }
+.sev-low { background: #388e3c; }
+.sev-info, .sev-none, .sev- { background: #757575; }
+.detail { color: #555; font-size: 12px; }
+pre.detail { margin: 0; white-space: pre-wrap; font-family: inherit; }
+</style>
+</head>
+<body>
+<h1>端口扫描报告</h1>
+<div class="meta">
+  目标: {{.Record.Target}} · 端口: {{.Record.PortSpec}} · 方式: {{.Record.ScanType}}<br>
+  开始: {{time .Record.StartedAt}} · 结束: {{time .Record.FinishedAt}} · 生成: {{time .Generated}}
+</div>
+<div class="cards">
+  <div class="card"><b>{{len .Hosts}}</b>主机</div>
+  <div class="card"><b>{{.Open}}</b>开放端口</div>
+  <div class="card"><b>{{.CVEs}}</b>CVE</div>
+  <div class="card"><b>{{.Findings}}</b>未授权访问</div>
+</div>
+{{range .Hosts}}
+<div class="host">
+  <h2>{{.Host}}</h2>
+  <table>
+    <tr><th>端口</th><th>状态</th><th>服务</th><th>产品/版本</th><th>详情</th><th>安全问题</th></tr>
+    {{range .Ports}}
+    <tr>
+      <td>{{.Port}}/{{.Protocol}}</td>
+      <td class="state-{{.State}}">{{.State}}</td>
+      <td>{{.Service}}{{if .TLS}} (tls){{end}}</td>
+      <td>{{.ProductName}} {{.Version}}{{if .Info}}<div class="detail">{{.Info}}</div>{{end}}{{if .CPE}}<div class="detail">{{.CPE}}</div>{{end}}</td>
+      <td class="detail">
+        {{with .HTTP}}<div>{{.StatusCode}} {{.Title}}{{if .Server}} · {{.Server}}{{end}}</div><div>{{.URL}}</div>{{end}}
+        {{with .Certificate}}<div>证书: {{.Subject}}，有效期至 {{time .NotAfter}}{{if .Expired}} (已过期){{end}}{{if .SelfSigned}} (自签名){{end}}</div>{{end}}
+        {{if .OperatingSystem}}<div>系统: {{.OperatingSystem}}</div>{{end}}
+        {{with .Details}}<pre class="detail">{{.String}}</pre>{{end}}
+      </td>
+      <td>
+        {{range .Findings}}<div><span class="sev sev-{{lower .Severity}}">{{.Severity}}</span> {{.Name}}<div class="detail">{{.Evidence}}</div></div>{{end}}
+        {{range .CVEs}}<div><span class="sev sev-{{lower .Severity}}">{{cvss .CVSS}}</span> <a href="https://nvd.nist.gov/vuln/detail/{{.ID}}">{{.ID}}</a></div>{{end}}
+      </td>
+    </tr>
+    {{end}}
+  </table>
+</div>
+{{else}}
+<p>没有发现开放端口</p>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,529 @@
+package portsscanner
+
+import (
+	"compress/gzip"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScanSummary 一次扫描的概况，保存在历史索引中
+type ScanSummary struct {
+	ID         string    `json:"id"`
+	Target     string    `json:"target"` // 目标表达式，同一目标的多次扫描可相互对比
+	PortSpec   string    `json:"port_spec"`
+	ScanType   string    `json:"scan_type"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Hosts      int       `json:"hosts"`      // 展开后的目标主机数
+	OpenPorts  int       `json:"open_ports"` // 开放端口数
+}
+
+// ScanRecord 一次扫描的完整结果
+type ScanRecord struct {
+	ScanSummary
+	Targets []string   `json:"targets"` // 主机发现前的全部目标地址与 IPv6 链路网段，用于判断对比范围
+	Stats   ScanStats  `json:"stats"`
+	Ports   []PortInfo `json:"ports"` // 开放端口及指纹识别结果
+	// 根据 TCP/IP 特征推测的主机操作系统
+	HostInfo []HostOSInfo `json:"host_info,omitempty"`
+}
+
+// 端口变化类型
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// PortChange 两次扫描之间单个端口的变化
+type PortChange struct {
+	Host     string    `json:"host"`
+	Port     int       `json:"port"`
+	Protocol string    `json:"protocol"`
+	Change   string    `json:"change"`           // added / removed / changed
+	Fields   []string  `json:"fields,omitempty"` // 发生变化的字段，仅 changed
+	Before   *PortInfo `json:"before,omitempty"`
+	After    *PortInfo `json:"after,omitempty"`
+}
+
+// ScanDiff 两次扫描的对比结果，只包含两次都扫描过的主机与端口
+type ScanDiff struct {
+	Base    ScanSummary  `json:"base"`
+	Compare ScanSummary  `json:"compare"`
+	Added   []PortChange `json:"added"`
+	Removed []PortChange `json:"removed"`
+	Changed []PortChange `json:"changed"`
+}
+
+var historyMutex sync.Mutex
+
+// historyDir 返回扫描历史目录，不存在时创建
+func historyDir() (string, error) {
+	dir, err := appDataDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "history")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("创建历史目录失败: %w", err)
+	}
+	return dir, nil
+}
+
+// newScanID 生成按时间排序的扫描 ID
+func newScanID() string {
+	buf := make([]byte, 3)
+	_, _ = rand.Read(buf)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(buf)
+}
+
+// validScanID 防止 ID 中出现路径分隔符
+func validScanID(id string) error {
+	if id == "" || strings.ContainsAny(id, `/\.`) {
+		return fmt.Errorf("无效的扫描 ID: %q", id)
+	}
+	return nil
+}
+
+// SaveScanRecord 保存扫描结果并更新历史索引
+func SaveScanRecord(record *ScanRecord) error {
+	if err := validScanID(record.ID); err != nil {
+		return err
+	}
+	record.OpenPorts = len(record.Ports)
+	record.Hosts = len(record.Targets)
+	sortPorts(record.Ports)
+
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	dir, err := historyDir()
+	if err != nil {
+		return err
+	}
+	if err := writeJSONGzip(filepath.Join(dir, record.ID+".json.gz"), record); err != nil {
+		return fmt.Errorf("保存扫描结果失败: %w", err)
+	}
+
+	index, err := readHistoryIndex(dir)
+	if err != nil {
+		return err
+	}
+	index = append(index, record.ScanSummary)
+	return writeHistoryIndex(dir, index)
+}
+
+// LoadScanRecord 读取指定的扫描结果
+func LoadScanRecord(id string) (*ScanRecord, error) {
+	if err := validScanID(id); err != nil {
+		return nil, err
+	}
+	dir, err := historyDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var record ScanRecord
+	err = readJSONGzip(filepath.Join(dir, id+".json.gz"), &record)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("扫描记录 %s 不存在", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取扫描记录失败: %w", err)
+	}
+	return &record, nil
+}
+
+// ListScanHistory 按时间倒序列出扫描历史，target 不为空时只返回该目标的记录
+func ListScanHistory(target string) ([]ScanSummary, error) {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	dir, err := historyDir()
+	if err != nil {
+		return nil, err
+	}
+	index, err := readHistoryIndex(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	target = strings.TrimSpace(target)
+	result := make([]ScanSummary, 0, len(index))
+	for i := len(index) - 1; i >= 0; i-- {
+		if target == "" || index[i].Target == target {
+			result = append(result, index[i])
+		}
+	}
+	return result, nil
+}
+
+// DeleteScanRecord 删除扫描结果及其索引项
+func DeleteScanRecord(id string) error {
+	if err := validScanID(id); err != nil {
+		return err
+	}
+
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	dir, err := historyDir()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(dir, id+".json.gz")); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	index, err := readHistoryIndex(dir)
+	if err != nil {
+		return err
+	}
+	kept := index[:0]
+	for _, summary := range index {
+		if summary.ID != id {
+			kept = append(kept, summary)
+		}
+	}
+	return writeHistoryIndex(dir, kept)
+}
+
+func readHistoryIndex(dir string) ([]ScanSummary, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var index []ScanSummary
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("扫描历史索引已损坏: %w", err)
+	}
+	return index, nil
+}
+
+func writeHistoryIndex(dir string, index []ScanSummary) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, "index.json")
+	if err := os.WriteFile(path+".tmp", data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// readJSONGzip 读取 gzip 压缩的 JSON 文件，文件不存在时返回 os.ErrNotExist
+func readJSONGzip(path string, v any) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	return json.NewDecoder(reader).Decode(v)
+}
+
+// writeJSONGzip 先写临时文件再替换，避免中断时留下损坏的文件
+func writeJSONGzip(path string, v any) error {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	writer := gzip.NewWriter(file)
+	err = json.NewEncoder(writer).Encode(v)
+	if closeErr := writer.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func sortPorts(ports []PortInfo) {
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Host != ports[j].Host {
+			return ports[i].Host < ports[j].Host
+		}
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+}
+
+func portKey(p PortInfo) string {
+	return p.Host + "/" + p.Protocol + "/" + strconv.Itoa(p.Port)
+}
+
+// DiffScans 对比两次扫描，base 为较早的一次。某个主机或端口只在其中一次的扫描范围内时不参与对比，
+// 避免端口范围或目标不同导致的误报
+func DiffScans(base, compare *ScanRecord) (*ScanDiff, error) {
+	baseScope, err := base.scope()
+	if err != nil {
+		return nil, err
+	}
+	compareScope, err := compare.scope()
+	if err != nil {
+		return nil, err
+	}
+	inScope := func(p PortInfo) bool {
+		return baseScope.contains(p) && compareScope.contains(p)
+	}
+
+	diff := &ScanDiff{
+		Base:    base.ScanSummary,
+		Compare: compare.ScanSummary,
+		Added:   []PortChange{},
+		Removed: []PortChange{},
+		Changed: []PortChange{},
+	}
+
+	before := make(map[string]*PortInfo, len(base.Ports))
+	for i := range base.Ports {
+		before[portKey(base.Ports[i])] = &base.Ports[i]
+	}
+	after := make(map[string]*PortInfo, len(compare.Ports))
+	for i := range compare.Ports {
+		after[portKey(compare.Ports[i])] = &compare.Ports[i]
+	}
+
+	for i := range compare.Ports {
+		p := &compare.Ports[i]
+		if !inScope(*p) {
+			continue
+		}
+		old, ok := before[portKey(*p)]
+		if !ok {
+			diff.Added = append(diff.Added, newPortChange(ChangeAdded, nil, p))
+			continue
+		}
+		if fields := changedFields(old, p); len(fields) > 0 {
+			change := newPortChange(ChangeChanged, old, p)
+			change.Fields = fields
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+	for i := range base.Ports {
+		p := &base.Ports[i]
+		if _, ok := after[portKey(*p)]; !ok && inScope(*p) {
+			diff.Removed = append(diff.Removed, newPortChange(ChangeRemoved, p, nil))
+		}
+	}
+	return diff, nil
+}
+
+func newPortChange(change string, before, after *PortInfo) PortChange {
+	ref := after
+	if ref == nil {
+		ref = before
+	}
+	return PortChange{
+		Host:     ref.Host,
+		Port:     ref.Port,
+		Protocol: ref.Protocol,
+		Change:   change,
+		Before:   before,
+		After:    after,
+	}
+}
+
+// changedFields 返回服务指纹中发生变化的字段，字段名与 PortInfo 的 JSON 名称一致
+func changedFields(before, after *PortInfo) []string {
+	var fields []string
+	check := func(name, a, b string) {
+		if a != b {
+			fields = append(fields, name)
+		}
+	}
+
+	check("service", before.Service, after.Service)
+	check("product_name", before.ProductName, after.ProductName)
+	check("version", before.Version, after.Version)
+	check("info", before.Info, after.Info)
+	check("operating_system", before.OperatingSystem, after.OperatingSystem)
+	check("tls", strconv.FormatBool(before.TLS), strconv.FormatBool(after.TLS))
+	check("cpe", before.CPE, after.CPE)
+
+	var beforeCert, afterCert string
+	if before.Certificate != nil {
+		beforeCert = before.Certificate.SerialNumber + "|" + before.Certificate.Issuer
+	}
+	if after.Certificate != nil {
+		afterCert = after.Certificate.SerialNumber + "|" + after.Certificate.Issuer
+	}
+	check("certificate", beforeCert, afterCert)
+
+	var beforeHTTP, afterHTTP string
+	if before.HTTP != nil {
+		beforeHTTP = strconv.Itoa(before.HTTP.StatusCode) + "|" + before.HTTP.Title
+	}
+	if after.HTTP != nil {
+		afterHTTP = strconv.Itoa(after.HTTP.StatusCode) + "|" + after.HTTP.Title
+	}
+	check("http", beforeHTTP, afterHTTP)
+
+	var beforeCVEs, afterCVEs []string
+	for _, cve := range before.CVEs {
+		beforeCVEs = append(beforeCVEs, cve.ID)
+	}
+	for _, cve := range after.CVEs {
+		afterCVEs = append(afterCVEs, cve.ID)
+	}
+	check("cves", sortedJoin(beforeCVEs), sortedJoin(afterCVEs))
+
+	var beforeFindings, afterFindings []string
+	for _, finding := range before.Findings {
+		beforeFindings = append(beforeFindings, finding.ID)
+	}
+	for _, finding := range after.Findings {
+		afterFindings = append(afterFindings, finding.ID)
+	}
+	check("findings", sortedJoin(beforeFindings), sortedJoin(afterFindings))
+	return fields
+}
+
+func sortedJoin(items []string) string {
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// scanScope 一次扫描覆盖的协议、主机与端口，networks 为由主机发现展开的 IPv6 链路网段
+type scanScope struct {
+	protocol string
+	hosts    map[string]bool
+	networks []*net.IPNet
+	ports    map[int]bool
+}
+
+func (r *ScanRecord) scope() (*scanScope, error) {
+	ports, err := ParsePortSpec(r.PortSpec)
+	if err != nil {
+		return nil, fmt.Errorf("扫描记录 %s 的端口表达式无效: %w", r.ID, err)
+	}
+	scope := &scanScope{
+		protocol: "tcp",
+		hosts:    make(map[string]bool, len(r.Targets)),
+		ports:    make(map[int]bool, len(ports)),
+	}
+	if r.ScanType == ScanTypeUDP {
+		scope.protocol = "udp"
+	}
+	for _, host := range r.Targets {
+		if _, ipNet, err := net.ParseCIDR(host); err == nil {
+			scope.networks = append(scope.networks, ipNet)
+			continue
+		}
+		scope.hosts[host] = true
+	}
+	for _, port := range ports {
+		scope.ports[port] = true
+	}
+	return scope, nil
+}
+
+func (s *scanScope) contains(p PortInfo) bool {
+	return p.Protocol == s.protocol && s.ports[p.Port] && s.containsHost(p.Host)
+}
+
+func (s *scanScope) containsHost(host string) bool {
+	if s.hosts[host] {
+		return true
+	}
+	ip := net.ParseIP(host)
+	for _, ipNet := range s.networks {
+		if ip != nil && ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffScanRecords 读取并对比两次扫描
+func DiffScanRecords(baseID, compareID string) (*ScanDiff, error) {
+	base, err := LoadScanRecord(baseID)
+	if err != nil {
+		return nil, err
+	}
+	compare, err := LoadScanRecord(compareID)
+	if err != nil {
+		return nil, err
+	}
+	if compare.StartedAt.Before(base.StartedAt) {
+		base, compare = compare, base
+	}
+	return DiffScans(base, compare)
+}
+
+// ExportScanDiff 按扩展名将对比结果导出为 CSV 或 JSON
+func ExportScanDiff(path string, diff *ScanDiff) error {
+	format, err := exportFormat(path)
+	if err != nil {
+		return err
+	}
+	if format != ExportCSV && format != ExportJSON {
+		return fmt.Errorf("对比结果只支持导出为 .csv 或 .json")
+	}
+
+	return writeExportFile(path, func(w io.Writer) error {
+		if format == ExportJSON {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(diff)
+		}
+
+		var rows [][]string
+		for _, changes := range [][]PortChange{diff.Added, diff.Removed, diff.Changed} {
+			for _, c := range changes {
+				rows = append(rows, []string{
+					c.Change, c.Host, strconv.Itoa(c.Port), c.Protocol,
+					strings.Join(c.Fields, ","), describePort(c.Before), describePort(c.After),
+				})
+			}
+		}
+		return writeCSV(w, []string{"change", "host", "port", "protocol", "fields", "before", "after"}, rows)
+	})
+}
+
+// describePort 返回端口指纹的简短描述，如 "http nginx 1.18.0"
+func describePort(p *PortInfo) string {
+	if p == nil {
+		return ""
+	}
+	var parts []string
+	for _, s := range []string{p.Service, p.ProductName, p.Version} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if p.HTTP != nil && p.HTTP.Title != "" {
+		parts = append(parts, "["+p.HTTP.Title+"]")
+	}
+	return strings.Join(parts, " ")
+}
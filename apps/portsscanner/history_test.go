@@ -0,0 +1,131 @@
+package portsscanner
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func testScanRecord(id, portSpec, scanType string, targets []string, ports ...PortInfo) *ScanRecord {
+	return &ScanRecord{
+		ScanSummary: ScanSummary{ID: id, PortSpec: portSpec, ScanType: scanType},
+		Targets:     targets,
+		Ports:       ports,
+	}
+}
+
+func tcpPort(host string, port int, service, version string) PortInfo {
+	return PortInfo{Host: host, Port: port, Protocol: "tcp", State: StateOpen, Service: service, Version: version}
+}
+
+// diffKeys 将对比结果整理为 变化类型:host/protocol/port 的列表
+func diffKeys(diff *ScanDiff) []string {
+	var keys []string
+	for _, changes := range [][]PortChange{diff.Added, diff.Removed, diff.Changed} {
+		for _, c := range changes {
+			key := c.Change + ":" + c.Host + "/" + c.Protocol + "/" + strconv.Itoa(c.Port)
+			for _, field := range c.Fields {
+				key += "," + field
+			}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestDiffScans(t *testing.T) {
+	hosts := []string{"10.0.0.1", "10.0.0.2"}
+	tests := []struct {
+		name          string
+		base, compare *ScanRecord
+		want          []string
+	}{
+		{
+			name:    "no changes",
+			base:    testScanRecord("a", "22,80", ScanTypeConnect, hosts, tcpPort("10.0.0.1", 22, "ssh", "8.9")),
+			compare: testScanRecord("b", "22,80", ScanTypeConnect, hosts, tcpPort("10.0.0.1", 22, "ssh", "8.9")),
+			want:    nil,
+		},
+		{
+			name: "added, removed and changed",
+			base: testScanRecord("a", "22,80,443", ScanTypeConnect, hosts,
+				tcpPort("10.0.0.1", 22, "ssh", "8.9"),
+				tcpPort("10.0.0.1", 80, "http", "")),
+			compare: testScanRecord("b", "22,80,443", ScanTypeConnect, hosts,
+				tcpPort("10.0.0.1", 22, "ssh", "9.6"),
+				tcpPort("10.0.0.2", 443, "https", "")),
+			want: []string{
+				"added:10.0.0.2/tcp/443",
+				"removed:10.0.0.1/tcp/80",
+				"changed:10.0.0.1/tcp/22,version",
+			},
+		},
+		{
+			name: "ports outside either port range are ignored",
+			base: testScanRecord("a", "top100", ScanTypeConnect, hosts,
+				tcpPort("10.0.0.1", 80, "http", "")),
+			compare: testScanRecord("b", "1-65535", ScanTypeConnect, hosts,
+				tcpPort("10.0.0.1", 80, "http", ""),
+				tcpPort("10.0.0.1", 31337, "unknown", ""),
+				tcpPort("10.0.0.1", 443, "https", "")),
+			want: []string{"added:10.0.0.1/tcp/443"},
+		},
+		{
+			name: "hosts outside either target list are ignored",
+			base: testScanRecord("a", "80", ScanTypeConnect, []string{"10.0.0.1"},
+				tcpPort("10.0.0.1", 80, "http", "")),
+			compare: testScanRecord("b", "80", ScanTypeConnect, []string{"10.0.0.2"},
+				tcpPort("10.0.0.2", 80, "http", "")),
+			want: nil,
+		},
+		{
+			name: "udp results are not compared with a tcp scan",
+			base: testScanRecord("a", "53", ScanTypeUDP, hosts,
+				PortInfo{Host: "10.0.0.1", Port: 53, Protocol: "udp", Service: "dns"}),
+			compare: testScanRecord("b", "53", ScanTypeConnect, hosts,
+				tcpPort("10.0.0.1", 53, "dns", "")),
+			want: nil,
+		},
+		{
+			name: "hosts discovered in an ipv6 link prefix are in scope",
+			base: testScanRecord("a", "22", ScanTypeConnect, []string{"fe80::/64"},
+				tcpPort("fe80::1", 22, "ssh", "")),
+			compare: testScanRecord("b", "22", ScanTypeConnect, []string{"fe80::/64"},
+				tcpPort("fe80::2", 22, "ssh", ""),
+				tcpPort("2001:db8::1", 22, "ssh", "")),
+			want: []string{"added:fe80::2/tcp/22", "removed:fe80::1/tcp/22"},
+		},
+		{
+			name: "certificate and http changes",
+			base: testScanRecord("a", "443", ScanTypeConnect, hosts, PortInfo{
+				Host: "10.0.0.1", Port: 443, Protocol: "tcp", Service: "https", TLS: true,
+				Certificate: &TLSInfo{SerialNumber: "01", Issuer: "CA"},
+				HTTP:        &HTTPInfo{StatusCode: 200, Title: "Home"},
+			}),
+			compare: testScanRecord("b", "443", ScanTypeConnect, hosts, PortInfo{
+				Host: "10.0.0.1", Port: 443, Protocol: "tcp", Service: "https", TLS: true,
+				Certificate: &TLSInfo{SerialNumber: "02", Issuer: "CA"},
+				HTTP:        &HTTPInfo{StatusCode: 302, Title: "Home"},
+			}),
+			want: []string{"changed:10.0.0.1/tcp/443,certificate,http"},
+		},
+	}
+	for _, tt := range tests {
+		diff, err := DiffScans(tt.base, tt.compare)
+		if err != nil {
+			t.Errorf("%s: DiffScans error: %v", tt.name, err)
+			continue
+		}
+		if got := diffKeys(diff); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: diff = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffScansInvalidPortSpec(t *testing.T) {
+	base := testScanRecord("a", "http", ScanTypeConnect, nil)
+	compare := testScanRecord("b", "80", ScanTypeConnect, nil)
+	if _, err := DiffScans(base, compare); err == nil {
+		t.Error("DiffScans with an invalid port spec returned no error")
+	}
+}
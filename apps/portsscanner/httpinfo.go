@@ -0,0 +1,258 @@
+package portsscanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"html"
+	"io"
+	"math/bits"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+const (
+	maxHTTPRedirects = 5
+	maxHTTPBodySize  = 1 << 20
+	maxTitleLength   = 200
+)
+
+// HTTPInfo Web 端口首页的探测结果
+type HTTPInfo struct {
+	URL           string   `json:"url"`
+	StatusCode    int      `json:"status_code"`
+	Title         string   `json:"title"`
+	Server        string   `json:"server,omitempty"`
+	PoweredBy     string   `json:"powered_by,omitempty"`
+	ContentType   string   `json:"content_type,omitempty"`
+	ContentLength int      `json:"content_length"`      // 实际读取到的响应体大小
+	Redirects     []string `json:"redirects,omitempty"` // 依次跳转到的地址
+	FaviconURL    string   `json:"favicon_url,omitempty"`
+	FaviconHash   int32    `json:"favicon_hash,omitempty"` // 与 Shodan/FOFA 一致的 mmh3 哈希
+}
+
+var (
+	titleRegexp   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	iconRegexp    = regexp.MustCompile(`(?is)<link[^>]+rel=["']?[^"'>]*icon[^"'>]*["']?[^>]*>`)
+	hrefRegexp    = regexp.MustCompile(`(?is)href=["']?([^"'\s>]+)`)
+	spacesRegexp  = regexp.MustCompile(`\s+`)
+	httpServiceRe = regexp.MustCompile(`^(ssl/)?https?(-|$)`)
+)
+
+// isHTTPService 判断端口是否为 Web 服务
+func isHTTPService(portInfo PortInfo) bool {
+	return httpServiceRe.MatchString(strings.ToLower(portInfo.Service))
+}
+
+// probeHTTP 请求首页并记录状态码、标题、关键响应头、跳转链与 favicon 哈希，
+// hostname 非空时作为 Host 头，便于命中基于域名的虚拟主机
+func probeHTTP(host string, port int, useTLS bool, hostname string, timeout time.Duration) (*HTTPInfo, error) {
+	scheme := "http"
+	if useTLS {
+		scheme = "https"
+	}
+	authority := net.JoinHostPort(host, strconv.Itoa(port))
+	if hostname != "" {
+		authority = net.JoinHostPort(hostname, strconv.Itoa(port))
+	}
+	target := scheme + "://" + authority + "/"
+
+	// 跳转与 favicon 只允许指向扫描的主机与端口，避免向目标及排除列表以外的主机发送请求
+	inScope := func(u *url.URL) bool {
+		name := u.Hostname()
+		if name != host && (hostname == "" || !strings.EqualFold(name, hostname)) {
+			return false
+		}
+		p := u.Port()
+		if p == "" {
+			p = "80"
+			if u.Scheme == "https" {
+				p = "443"
+			}
+		}
+		return p == strconv.Itoa(port)
+	}
+
+	info := &HTTPInfo{URL: target}
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			// 始终连接扫描到的地址，Host 头使用域名
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				if hostname != "" && strings.HasPrefix(addr, hostname+":") {
+					addr = net.JoinHostPort(host, addr[len(hostname)+1:])
+				}
+				dialer := net.Dialer{Timeout: timeout}
+				return dialer.DialContext(ctx, network, addr)
+			},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxHTTPRedirects {
+				return http.ErrUseLastResponse
+			}
+			info.Redirects = append(info.Redirects, req.URL.String())
+			if !inScope(req.URL) {
+				// 只记录跳转地址，不再跟随
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	resp, err := httpGet(client, target)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+	info.StatusCode = resp.StatusCode
+	info.Server = resp.Header.Get("Server")
+	info.PoweredBy = resp.Header.Get("X-Powered-By")
+	info.ContentType = resp.Header.Get("Content-Type")
+	info.ContentLength = len(body)
+
+	body = decodeBody(body, info.ContentType)
+	info.Title = extractTitle(body)
+
+	info.FaviconURL = faviconURL(resp.Request.URL, body)
+	if u, err := url.Parse(info.FaviconURL); err != nil || !inScope(u) {
+		info.FaviconURL = ""
+	} else if hash, ok := fetchFaviconHash(client, info.FaviconURL); ok {
+		info.FaviconHash = hash
+	} else {
+		info.FaviconURL = ""
+	}
+	return info, nil
+}
+
+func httpGet(client *http.Client, target string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36")
+	req.Header.Set("Accept", "*/*")
+	return client.Do(req)
+}
+
+// decodeBody 根据响应头与 meta 标签将 GBK 等编码的页面转换为 UTF-8
+func decodeBody(body []byte, contentType string) []byte {
+	encoding, name, _ := charset.DetermineEncoding(body, contentType)
+	if name == "utf-8" || name == "windows-1252" {
+		return body
+	}
+	decoded, err := io.ReadAll(encoding.NewDecoder().Reader(bytes.NewReader(body)))
+	if err != nil {
+		return body
+	}
+	return decoded
+}
+
+func extractTitle(body []byte) string {
+	match := titleRegexp.FindSubmatch(body)
+	if match == nil {
+		return ""
+	}
+	title := strings.TrimSpace(spacesRegexp.ReplaceAllString(html.UnescapeString(string(match[1])), " "))
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = string(runes[:maxTitleLength])
+	}
+	return title
+}
+
+// faviconURL 优先使用页面声明的图标，否则使用站点根目录下的 favicon.ico
+func faviconURL(base *url.URL, body []byte) string {
+	if link := iconRegexp.Find(body); link != nil {
+		if href := hrefRegexp.FindSubmatch(link); href != nil {
+			if ref, err := url.Parse(html.UnescapeString(string(href[1]))); err == nil {
+				return base.ResolveReference(ref).String()
+			}
+		}
+	}
+	return base.ResolveReference(&url.URL{Path: "/favicon.ico"}).String()
+}
+
+func fetchFaviconHash(client *http.Client, target string) (int32, bool) {
+	resp, err := httpGet(client, target)
+	if err != nil {
+		return 0, false
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+	if err != nil || resp.StatusCode != http.StatusOK || len(data) == 0 {
+		return 0, false
+	}
+	return faviconHash(data), true
+}
+
+// faviconHash 按 Shodan 的方式计算: 对每 76 字符换行的 base64 编码取 mmh3 32 位哈希
+func faviconHash(data []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var b strings.Builder
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteByte('\n')
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	b.WriteByte('\n')
+	return int32(murmur3([]byte(b.String()), 0))
+}
+
+// murmur3 MurmurHash3 x86 32 位实现
+func murmur3(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+
+	h := seed
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[n*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
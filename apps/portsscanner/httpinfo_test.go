@@ -0,0 +1,51 @@
+package portsscanner
+
+import "testing"
+
+func TestMurmur3(t *testing.T) {
+	// MurmurHash3 x86_32 参考实现的测试向量
+	tests := []struct {
+		data string
+		seed uint32
+		want uint32
+	}{
+		{"", 0, 0},
+		{"", 1, 0x514e28b7},
+		{"", 0xffffffff, 0x81f16f39},
+		{"\xff\xff\xff\xff", 0, 0x76293b50},
+		{"\x21\x43\x65\x87", 0, 0xf55b516b},
+		{"\x21\x43\x65", 0, 0x7e4a8634},
+		{"\x21\x43", 0, 0xa0f7b07a},
+		{"\x21", 0, 0x72661cf4},
+		{"\x00\x00\x00\x00", 0, 0x2362f9de},
+		{"aaaa", 0x9747b28c, 0x5a97808a},
+		{"Hello, world!", 1234, 0xfaf6cdb3},
+		{"The quick brown fox jumps over the lazy dog", 0, 0x2e4ff723},
+	}
+	for _, tt := range tests {
+		if got := murmur3([]byte(tt.data), tt.seed); got != tt.want {
+			t.Errorf("murmur3(%q, %#x) = %#x, want %#x", tt.data, tt.seed, got, tt.want)
+		}
+	}
+}
+
+func TestFaviconHash(t *testing.T) {
+	// 期望值与 Shodan 的计算方式一致: mmh3.hash(base64.encodebytes(data))
+	allBytes := make([]byte, 256)
+	for i := range allBytes {
+		allBytes[i] = byte(i)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want int32
+	}{
+		{"single line", []byte("\x00\x01favicon"), 1504793308},
+		{"wrapped at 76 chars", allBytes, -757223386},
+	}
+	for _, tt := range tests {
+		if got := faviconHash(tt.data); got != tt.want {
+			t.Errorf("%s: faviconHash = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package portsscanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ImportedPort 从 nmap / masscan 输出中导入的开放端口
+type ImportedPort struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`         // tcp / udp
+	Domain   string `json:"domain,omitempty"` // nmap 输出中的主机名，用于 TLS SNI
+}
+
+// 支持导入的格式
+const (
+	ImportNmapXML     = "nmap-xml" // nmap -oX 与 masscan -oX
+	ImportMasscanJSON = "masscan-json"
+	ImportMasscanList = "masscan-list"
+	ImportGrepable    = "grepable" // nmap -oG 与 masscan -oG
+	ImportHostPort    = "host-port"
+)
+
+// LoadScanOutput 读取并解析 nmap / masscan 输出文件
+func LoadScanOutput(path string) ([]ImportedPort, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("读取导入文件失败: %w", err)
+	}
+	return ParseScanOutput(data)
+}
+
+// ParseScanOutput 自动识别格式并解析出开放端口，返回去重排序后的结果与识别出的格式
+func ParseScanOutput(data []byte) ([]ImportedPort, string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimSpace(data)
+
+	var (
+		ports  []ImportedPort
+		format string
+		err    error
+	)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		format = ImportNmapXML
+		ports, err = parseNmapXML(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("[")) || bytes.HasPrefix(trimmed, []byte("{")):
+		format = ImportMasscanJSON
+		ports, err = parseMasscanJSON(trimmed)
+	case grepableRegexp.Match(trimmed):
+		format = ImportGrepable
+		ports = parseGrepable(trimmed)
+	case masscanListRegexp.Match(trimmed):
+		format = ImportMasscanList
+		ports = parseMasscanList(trimmed)
+	default:
+		format = ImportHostPort
+		ports = parseHostPortList(trimmed)
+	}
+	if err != nil {
+		return nil, format, err
+	}
+
+	ports = dedupImportedPorts(ports)
+	if len(ports) == 0 {
+		return nil, format, fmt.Errorf("导入文件中没有开放端口")
+	}
+	return ports, format, nil
+}
+
+func parseNmapXML(data []byte) ([]ImportedPort, error) {
+	var run nmapRun
+	if err := xml.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("解析 XML 失败: %w", err)
+	}
+
+	var ports []ImportedPort
+	for _, host := range run.Hosts {
+		var addr, domain string
+		for _, address := range host.Addresses {
+			// 跳过 MAC 地址
+			if address.AddrType == "ipv4" || address.AddrType == "ipv6" {
+				addr = address.Addr
+				break
+			}
+		}
+		if addr == "" {
+			continue
+		}
+		for _, hostname := range host.Hostnames {
+			if hostname.Type == "user" || domain == "" {
+				domain = hostname.Name
+			}
+		}
+		for _, port := range host.Ports {
+			if port.State.State == StateOpen {
+				ports = append(ports, ImportedPort{Host: addr, Port: port.PortID, Protocol: port.Protocol, Domain: domain})
+			}
+		}
+	}
+	return ports, nil
+}
+
+type masscanRecord struct {
+	IP    string `json:"ip"`
+	Ports []struct {
+		Port   int    `json:"port"`
+		Proto  string `json:"proto"`
+		Status string `json:"status"`
+	} `json:"ports"`
+}
+
+// parseMasscanJSON 解析 masscan -oJ 输出。旧版本 masscan 输出的数组末尾多一个逗号，
+// 无法整体解析时按行解析，同时兼容 --output-format ndjson
+func parseMasscanJSON(data []byte) ([]ImportedPort, error) {
+	var records []masscanRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		var record masscanRecord
+		if json.Unmarshal(data, &record) == nil && record.IP != "" {
+			records = []masscanRecord{record}
+		} else {
+			records = records[:0]
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimRight(strings.TrimSpace(line), ",")
+				if !strings.HasPrefix(line, "{") {
+					continue
+				}
+				var record masscanRecord
+				if err := json.Unmarshal([]byte(line), &record); err != nil {
+					return nil, fmt.Errorf("解析 masscan JSON 失败: %w", err)
+				}
+				records = append(records, record)
+			}
+		}
+	}
+
+	var ports []ImportedPort
+	for _, record := range records {
+		for _, port := range record.Ports {
+			if port.Status == "" || port.Status == StateOpen {
+				ports = append(ports, ImportedPort{Host: record.IP, Port: port.Port, Protocol: port.Proto})
+			}
+		}
+	}
+	return ports, nil
+}
+
+var (
+	// masscan -oL: "open tcp 80 192.168.1.1 1700000000"
+	masscanListRegexp = regexp.MustCompile(`(?m)^open\s+(tcp|udp)\s+\d+\s+\S+`)
+	// nmap / masscan -oG: "Host: 192.168.1.1 ()	Ports: 22/open/tcp//ssh///, 80/open/tcp//http///"
+	grepableRegexp = regexp.MustCompile(`(?m)^(?:Timestamp:\s*\d+\s+)?Host:\s+\S+.*\tPorts:`)
+	grepableHost   = regexp.MustCompile(`Host:\s+(\S+)\s+\(([^)]*)\)`)
+)
+
+func parseMasscanList(data []byte) []ImportedPort {
+	var ports []ImportedPort
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 || fields[0] != StateOpen {
+			continue
+		}
+		if port, err := strconv.Atoi(fields[2]); err == nil {
+			ports = append(ports, ImportedPort{Host: fields[3], Port: port, Protocol: fields[1]})
+		}
+	}
+	return ports
+}
+
+func parseGrepable(data []byte) []ImportedPort {
+	var ports []ImportedPort
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		host := grepableHost.FindStringSubmatch(line)
+		_, list, ok := strings.Cut(line, "Ports: ")
+		if host == nil || !ok {
+			continue
+		}
+		// Ports 字段之后可能还有以制表符分隔的 Ignored State 等字段
+		list, _, _ = strings.Cut(list, "\t")
+
+		for _, item := range strings.Split(list, ",") {
+			// 端口/状态/协议/owner/服务/rpc/版本
+			fields := strings.Split(strings.TrimSpace(item), "/")
+			if len(fields) < 3 || fields[1] != StateOpen {
+				continue
+			}
+			if port, err := strconv.Atoi(fields[0]); err == nil {
+				ports = append(ports, ImportedPort{Host: host[1], Port: port, Protocol: fields[2], Domain: host[2]})
+			}
+		}
+	}
+	return ports
+}
+
+// parseHostPortList 解析每行一个 host:port 的列表，如 "192.168.1.1:80"、"[::1]:22"
+func parseHostPortList(data []byte) []ImportedPort {
+	var ports []ImportedPort
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		host, portStr, err := net.SplitHostPort(line)
+		if err != nil {
+			continue
+		}
+		if port, err := strconv.Atoi(portStr); err == nil {
+			ports = append(ports, ImportedPort{Host: host, Port: port, Protocol: "tcp"})
+		}
+	}
+	return ports
+}
+
+// dedupImportedPorts 校验地址与端口，去重后按主机、端口排序
+func dedupImportedPorts(ports []ImportedPort) []ImportedPort {
+	seen := make(map[string]bool)
+	result := make([]ImportedPort, 0, len(ports))
+	for _, p := range ports {
+		p.Protocol = strings.ToLower(p.Protocol)
+		if p.Protocol == "" {
+			p.Protocol = "tcp"
+		}
+		if p.Protocol != "tcp" && p.Protocol != "udp" {
+			continue
+		}
+		if net.ParseIP(p.Host) == nil || p.Port < 1 || p.Port > 65535 {
+			continue
+		}
+		key := p.Host + "/" + p.Protocol + "/" + strconv.Itoa(p.Port)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, p)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Host != result[j].Host {
+			return result[i].Host < result[j].Host
+		}
+		if result[i].Port != result[j].Port {
+			return result[i].Port < result[j].Port
+		}
+		return result[i].Protocol < result[j].Protocol
+	})
+	return result
+}
+
+// importedScope 返回导入结果涉及的主机与端口，用于记录扫描范围
+func importedScope(ports []ImportedPort) ([]Target, []int) {
+	var targets []Target
+	var portList []int
+	seen := make(map[string]bool)
+	for _, p := range ports {
+		if !seen[p.Host] {
+			seen[p.Host] = true
+			targets = append(targets, Target{IP: p.Host})
+		}
+		portList = append(portList, p.Port)
+	}
+	portList = dedupPorts(portList)
+	sort.Ints(portList)
+	return targets, portList
+}
+
+// importedScanType 全部为 UDP 端口时按 UDP 扫描记录，否则按 TCP 全连接记录
+func importedScanType(ports []ImportedPort) string {
+	for _, p := range ports {
+		if p.Protocol != "udp" {
+			return ScanTypeConnect
+		}
+	}
+	return ScanTypeUDP
+}
+
+// scanTotal 返回需要探测的端口总数，导入时只探测导入的端口
+func scanTotal(targets []Target, ports []int, imported []ImportedPort) int {
+	if imported != nil {
+		return len(imported)
+	}
+	return len(targets) * len(ports)
+}
+
+// FingerprintPorts 跳过端口发现，只对导入的端口执行 gonmap 指纹识别及后续的证书、Web、CVE 与未授权检查。
+// TCP 端口的探活连接直接用于读取 Banner，端口已不再开放时按实际状态上报
+func FingerprintPorts(ctx context.Context, config ScanConfig, ports []ImportedPort, callback PortCallback) (*ScanStats, error) {
+	if callback == nil {
+		return nil, fmt.Errorf("callback function cannot be nil")
+	}
+	if config.MaxThreads <= 0 {
+		return nil, fmt.Errorf("线程数必须大于0")
+	}
+
+	// 导入的端口已确认开放，不需要 SYN 扫描
+	config.ScanType = ScanTypeConnect
+	scanner, err := newPortScanner(config)
+	if err != nil {
+		return nil, err
+	}
+	defer scanner.Close()
+
+	excludes, err := config.ResolveExcludes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ports, excluded := excludes.FilterImported(ports)
+
+	for _, p := range ports {
+		if p.Domain != "" {
+			scanner.domains[p.Host] = p.Domain
+		}
+	}
+
+	targets, _ := importedScope(ports)
+	detector := config.startOSDetect(targets)
+	defer detector.Close()
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, config.MaxThreads)
+	stats, tracker := config.newTracker(len(ports))
+	atomic.AddInt32(&stats.Excluded, int32(excluded))
+
+	for index, p := range ports {
+		if config.Checkpoint != nil && config.Checkpoint.isDone(index) {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			tracker.stop("cancelled")
+			return stats, context.Canceled
+		default:
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+
+		go func(index int, p ImportedPort) {
+			defer func() {
+				wg.Done()
+				<-semaphore
+				if r := recover(); r != nil {
+					fmt.Printf("Recovered from panic in fingerprint goroutine: %v\n", r)
+				}
+			}()
+
+			var portInfo PortInfo
+			if p.Protocol == "udp" {
+				portInfo = scanner.udpPortState(ctx, p.Host, p.Port)
+			} else {
+				portInfo = scanner.connectPort(ctx, p.Host, p.Port)
+			}
+			if portInfo.State == "" {
+				return
+			}
+			config.finishPort(ctx, index, portInfo, stats, tracker, callback)
+		}(index, p)
+	}
+
+	wg.Wait()
+	if ctx.Err() != nil {
+		tracker.stop("cancelled")
+		return stats, context.Canceled
+	}
+	tracker.stop("completed")
+	detector.report(targets, config.HostInfo)
+	return stats, nil
+}
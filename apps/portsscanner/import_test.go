@@ -0,0 +1,150 @@
+package portsscanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testNmapXML = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE nmaprun>
+<nmaprun scanner="nmap" args="nmap -sV -oX - example.com" start="1700000000" version="7.94" xmloutputversion="1.05">
+<host><status state="up" reason="syn-ack"/>
+<address addr="93.184.216.34" addrtype="ipv4"/>
+<address addr="00:11:22:33:44:55" addrtype="mac"/>
+<hostnames><hostname name="93-184-216-34.example.net" type="PTR"/><hostname name="example.com" type="user"/></hostnames>
+<ports>
+<port protocol="tcp" portid="443"><state state="open" reason="syn-ack" reason_ttl="54"/><service name="https"/></port>
+<port protocol="tcp" portid="80"><state state="open" reason="syn-ack" reason_ttl="54"/><service name="http"/></port>
+<port protocol="tcp" portid="22"><state state="filtered" reason="no-response" reason_ttl="0"/></port>
+<port protocol="udp" portid="53"><state state="open" reason="udp-response" reason_ttl="54"/></port>
+</ports>
+</host>
+<host><status state="up" reason="echo-reply"/>
+<address addr="2001:db8::1" addrtype="ipv6"/>
+<ports><port protocol="tcp" portid="22"><state state="open" reason="syn-ack" reason_ttl="64"/></port></ports>
+</host>
+</nmaprun>`
+
+func TestParseScanOutput(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		format string
+		want   []ImportedPort
+	}{
+		{
+			name:   "nmap xml",
+			data:   testNmapXML,
+			format: ImportNmapXML,
+			want: []ImportedPort{
+				{Host: "2001:db8::1", Port: 22, Protocol: "tcp"},
+				{Host: "93.184.216.34", Port: 53, Protocol: "udp", Domain: "example.com"},
+				{Host: "93.184.216.34", Port: 80, Protocol: "tcp", Domain: "example.com"},
+				{Host: "93.184.216.34", Port: 443, Protocol: "tcp", Domain: "example.com"},
+			},
+		},
+		{
+			name: "masscan json",
+			data: `[
+{   "ip": "10.0.0.2",   "timestamp": "1700000000", "ports": [ {"port": 80, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 64} ] },
+{   "ip": "10.0.0.1",   "timestamp": "1700000000", "ports": [ {"port": 22, "proto": "tcp", "status": "open", "reason": "syn-ack", "ttl": 64} ] }
+]`,
+			format: ImportMasscanJSON,
+			want: []ImportedPort{
+				{Host: "10.0.0.1", Port: 22, Protocol: "tcp"},
+				{Host: "10.0.0.2", Port: 80, Protocol: "tcp"},
+			},
+		},
+		{
+			name: "masscan json with trailing comma",
+			data: `[
+{   "ip": "10.0.0.1",   "timestamp": "1700000000", "ports": [ {"port": 443, "proto": "tcp", "status": "open"} ] },
+{   "ip": "10.0.0.1",   "timestamp": "1700000000", "ports": [ {"port": 161, "proto": "udp", "status": "open"} ] },
+]`,
+			format: ImportMasscanJSON,
+			want: []ImportedPort{
+				{Host: "10.0.0.1", Port: 161, Protocol: "udp"},
+				{Host: "10.0.0.1", Port: 443, Protocol: "tcp"},
+			},
+		},
+		{
+			name: "masscan ndjson",
+			data: `{"ip":"10.0.0.3","timestamp":"1700000000","ports":[{"port":3306,"proto":"tcp","status":"open"}]}
+{"ip":"10.0.0.3","timestamp":"1700000000","ports":[{"port":6379,"proto":"tcp","status":"closed"}]}`,
+			format: ImportMasscanJSON,
+			want:   []ImportedPort{{Host: "10.0.0.3", Port: 3306, Protocol: "tcp"}},
+		},
+		{
+			name: "masscan list",
+			data: `#masscan
+open tcp 8080 10.0.0.5 1700000000
+open udp 53 10.0.0.5 1700000000
+open tcp 8080 10.0.0.5 1700000001
+# end
+`,
+			format: ImportMasscanList,
+			want: []ImportedPort{
+				{Host: "10.0.0.5", Port: 53, Protocol: "udp"},
+				{Host: "10.0.0.5", Port: 8080, Protocol: "tcp"},
+			},
+		},
+		{
+			name: "nmap grepable",
+			data: "# Nmap 7.94 scan initiated as: nmap -oG - 10.0.0.6\n" +
+				"Host: 10.0.0.6 (router.lan)\tStatus: Up\n" +
+				"Host: 10.0.0.6 (router.lan)\tPorts: 22/open/tcp//ssh///, 23/closed/tcp//telnet///, 161/open/udp//snmp///\tIgnored State: closed (997)\n" +
+				"# Nmap done at Tue Nov 14 00:00:00 2023 -- 1 IP address (1 host up) scanned\n",
+			format: ImportGrepable,
+			want: []ImportedPort{
+				{Host: "10.0.0.6", Port: 22, Protocol: "tcp", Domain: "router.lan"},
+				{Host: "10.0.0.6", Port: 161, Protocol: "udp", Domain: "router.lan"},
+			},
+		},
+		{
+			name:   "masscan grepable",
+			data:   "# Masscan 1.3.2 scan initiated\nTimestamp: 1700000000\tHost: 10.0.0.7 ()\tPorts: 443/open/tcp//https//\n",
+			format: ImportGrepable,
+			want:   []ImportedPort{{Host: "10.0.0.7", Port: 443, Protocol: "tcp"}},
+		},
+		{
+			name:   "host port list",
+			data:   "\xef\xbb\xbf# targets\n10.0.0.8:80\n[2001:db8::8]:22\nexample.com:443\n10.0.0.8:70000\n",
+			format: ImportHostPort,
+			want: []ImportedPort{
+				{Host: "10.0.0.8", Port: 80, Protocol: "tcp"},
+				{Host: "2001:db8::8", Port: 22, Protocol: "tcp"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got, format, err := ParseScanOutput([]byte(tt.data))
+		if err != nil {
+			t.Errorf("%s: ParseScanOutput error: %v", tt.name, err)
+			continue
+		}
+		if format != tt.format {
+			t.Errorf("%s: format = %q, want %q", tt.name, format, tt.format)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ports = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseScanOutputErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"invalid xml", "<nmaprun><host>"},
+		{"invalid json", "[{\"ip\": }]"},
+		{"no open ports", "open tcp 99999 10.0.0.1 1700000000\n"},
+		{"no hosts", "<nmaprun></nmaprun>"},
+	}
+	for _, tt := range tests {
+		if got, _, err := ParseScanOutput([]byte(tt.data)); err == nil {
+			t.Errorf("%s: ParseScanOutput = %+v, want error", tt.name, got)
+		}
+	}
+}
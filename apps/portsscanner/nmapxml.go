@@ -0,0 +1,315 @@
+package portsscanner
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 以下类型对应 nmap -oX 输出中用到的元素，字段名与 nmap.dtd 一致
+
+type nmapRun struct {
+	XMLName          xml.Name       `xml:"nmaprun"`
+	Scanner          string         `xml:"scanner,attr"`
+	Args             string         `xml:"args,attr"`
+	Start            int64          `xml:"start,attr"`
+	StartStr         string         `xml:"startstr,attr"`
+	Version          string         `xml:"version,attr"`
+	XMLOutputVersion string         `xml:"xmloutputversion,attr"`
+	ScanInfo         []nmapScanInfo `xml:"scaninfo"`
+	Hosts            []nmapHost     `xml:"host"`
+	RunStats         nmapRunStats   `xml:"runstats"`
+}
+
+type nmapScanInfo struct {
+	Type        string `xml:"type,attr"`
+	Protocol    string `xml:"protocol,attr"`
+	NumServices int    `xml:"numservices,attr"`
+	Services    string `xml:"services,attr"`
+}
+
+type nmapHost struct {
+	StartTime int64          `xml:"starttime,attr,omitempty"`
+	EndTime   int64          `xml:"endtime,attr,omitempty"`
+	Status    nmapStatus     `xml:"status"`
+	Addresses []nmapAddress  `xml:"address"`
+	Hostnames []nmapHostname `xml:"hostnames>hostname"`
+	Ports     []nmapPort     `xml:"ports>port"`
+}
+
+type nmapStatus struct {
+	State  string `xml:"state,attr"`
+	Reason string `xml:"reason,attr"`
+}
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type nmapPort struct {
+	Protocol string       `xml:"protocol,attr"`
+	PortID   int          `xml:"portid,attr"`
+	State    nmapState    `xml:"state"`
+	Service  *nmapService `xml:"service"`
+	Scripts  []nmapScript `xml:"script"`
+}
+
+type nmapState struct {
+	State     string `xml:"state,attr"`
+	Reason    string `xml:"reason,attr"`
+	ReasonTTL int    `xml:"reason_ttl,attr"`
+}
+
+type nmapService struct {
+	Name       string   `xml:"name,attr"`
+	Product    string   `xml:"product,attr,omitempty"`
+	Version    string   `xml:"version,attr,omitempty"`
+	ExtraInfo  string   `xml:"extrainfo,attr,omitempty"`
+	Hostname   string   `xml:"hostname,attr,omitempty"`
+	OSType     string   `xml:"ostype,attr,omitempty"`
+	DeviceType string   `xml:"devicetype,attr,omitempty"`
+	Tunnel     string   `xml:"tunnel,attr,omitempty"`
+	Method     string   `xml:"method,attr"`
+	Conf       int      `xml:"conf,attr"`
+	CPEs       []string `xml:"cpe"`
+}
+
+type nmapScript struct {
+	ID     string `xml:"id,attr"`
+	Output string `xml:"output,attr"`
+}
+
+type nmapRunStats struct {
+	Finished nmapFinished  `xml:"finished"`
+	Hosts    nmapHostStats `xml:"hosts"`
+}
+
+type nmapFinished struct {
+	Time    int64  `xml:"time,attr"`
+	TimeStr string `xml:"timestr,attr"`
+	Elapsed string `xml:"elapsed,attr"`
+	Summary string `xml:"summary,attr"`
+	Exit    string `xml:"exit,attr"`
+}
+
+type nmapHostStats struct {
+	Up    int `xml:"up,attr"`
+	Down  int `xml:"down,attr"`
+	Total int `xml:"total,attr"`
+}
+
+// nmap 的 scaninfo type 取值
+var nmapScanTypes = map[string]string{
+	"":              "connect",
+	ScanTypeConnect: "connect",
+	ScanTypeSYN:     "syn",
+	ScanTypeUDP:     "udp",
+}
+
+// 对应的 nmap 命令行参数
+var nmapScanFlags = map[string]string{
+	"connect": "-sT",
+	"syn":     "-sS",
+	"udp":     "-sU",
+}
+
+// 与 nmap 输出的 state reason 对应
+var nmapReasons = map[string]string{
+	StateOpen:         "syn-ack",
+	StateClosed:       "conn-refused",
+	StateFiltered:     "no-response",
+	StateOpenFiltered: "no-response",
+}
+
+// writeNmapXML 按 nmap -oX 格式输出扫描结果。scanner 取 "nmap"，
+// 以便只接受 nmap 输出的工具(如 Metasploit db_import、Zenmap)可以直接加载
+func writeNmapXML(w io.Writer, record *ScanRecord) error {
+	started := record.StartedAt
+	if started.IsZero() {
+		started = time.Now()
+	}
+	finished := record.FinishedAt
+	if finished.IsZero() {
+		finished = started
+	}
+
+	scanType := nmapScanTypes[record.ScanType]
+	protocol := "tcp"
+	if record.ScanType == ScanTypeUDP {
+		protocol = "udp"
+	}
+	run := nmapRun{
+		Scanner:          "nmap",
+		Args:             fmt.Sprintf("GlideWay %s -p %s %s", nmapScanFlags[scanType], record.PortSpec, record.Target),
+		Start:            started.Unix(),
+		StartStr:         started.Format(time.ANSIC),
+		Version:          "7.94",
+		XMLOutputVersion: "1.05",
+	}
+	if ports, err := ParsePortSpec(record.PortSpec); err == nil {
+		run.ScanInfo = []nmapScanInfo{{
+			Type:        scanType,
+			Protocol:    protocol,
+			NumServices: len(ports),
+			Services:    portRangeString(ports),
+		}}
+	}
+
+	// 按主机分组，保持端口顺序
+	ports := append([]PortInfo(nil), record.Ports...)
+	sortPorts(ports)
+	hostIndex := make(map[string]int)
+	for _, p := range ports {
+		i, ok := hostIndex[p.Host]
+		if !ok {
+			addrType := "ipv4"
+			if ip := net.ParseIP(p.Host); ip != nil && ip.To4() == nil {
+				addrType = "ipv6"
+			}
+			run.Hosts = append(run.Hosts, nmapHost{
+				StartTime: started.Unix(),
+				EndTime:   finished.Unix(),
+				Status:    nmapStatus{State: "up", Reason: "user-set"},
+				Addresses: []nmapAddress{{Addr: p.Host, AddrType: addrType}},
+			})
+			i = len(run.Hosts) - 1
+			hostIndex[p.Host] = i
+		}
+		run.Hosts[i].Ports = append(run.Hosts[i].Ports, toNmapPort(p))
+	}
+
+	total := len(record.Targets)
+	if total < len(run.Hosts) {
+		total = len(run.Hosts)
+	}
+	run.RunStats = nmapRunStats{
+		Finished: nmapFinished{
+			Time:    finished.Unix(),
+			TimeStr: finished.Format(time.ANSIC),
+			Elapsed: fmt.Sprintf("%.2f", finished.Sub(started).Seconds()),
+			Summary: fmt.Sprintf("GlideWay done at %s; %d IP addresses (%d hosts up)", finished.Format(time.ANSIC), total, len(run.Hosts)),
+			Exit:    "success",
+		},
+		Hosts: nmapHostStats{Up: len(run.Hosts), Down: total - len(run.Hosts), Total: total},
+	}
+
+	if _, err := io.WriteString(w, xml.Header+"<!DOCTYPE nmaprun>\n"); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(run); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func toNmapPort(p PortInfo) nmapPort {
+	port := nmapPort{
+		Protocol: p.Protocol,
+		PortID:   p.Port,
+		State:    nmapState{State: p.State, Reason: nmapReasons[p.State]},
+	}
+	if p.Protocol == "udp" && p.State == StateOpen {
+		port.State.Reason = "udp-response"
+	}
+	if p.Service == "" {
+		return port
+	}
+
+	service := &nmapService{
+		Name:       p.Service,
+		Product:    p.ProductName,
+		Version:    p.Version,
+		ExtraInfo:  p.Info,
+		Hostname:   p.Hostname,
+		OSType:     p.OperatingSystem,
+		DeviceType: p.DeviceType,
+		Method:     "probed",
+		Conf:       10,
+	}
+	if p.ProductName == "" {
+		// 只按端口号推断的服务名
+		service.Method, service.Conf = "table", 3
+	}
+	if p.TLS {
+		service.Tunnel = "ssl"
+	}
+	if cpe, ok := parseCPE(p.CPE); ok {
+		service.CPEs = []string{cpe.URI()}
+	}
+	port.Service = service
+
+	if p.Banner != "" {
+		port.Scripts = append(port.Scripts, nmapScript{ID: "banner", Output: p.Banner})
+	}
+	if p.HTTP != nil {
+		if p.HTTP.Title != "" {
+			port.Scripts = append(port.Scripts, nmapScript{ID: "http-title", Output: p.HTTP.Title})
+		}
+		if p.HTTP.Server != "" {
+			port.Scripts = append(port.Scripts, nmapScript{ID: "http-server-header", Output: p.HTTP.Server})
+		}
+	}
+	if cert := p.Certificate; cert != nil {
+		output := fmt.Sprintf("Subject: %s\nIssuer: %s\nPublic Key type: %s\nPublic Key bits: %d\nNot valid before: %s\nNot valid after:  %s",
+			cert.Subject, cert.Issuer, cert.KeyType, cert.KeySize,
+			cert.NotBefore.UTC().Format("2006-01-02T15:04:05"), cert.NotAfter.UTC().Format("2006-01-02T15:04:05"))
+		if len(cert.SANs) > 0 {
+			output += "\nSubject Alternative Name: " + strings.Join(cert.SANs, ", ")
+		}
+		port.Scripts = append(port.Scripts, nmapScript{ID: "ssl-cert", Output: output})
+	}
+	if len(p.Details) > 0 {
+		port.Scripts = append(port.Scripts, nmapScript{ID: "glideway-details", Output: p.Details.String()})
+	}
+	if cpe, ok := parseCPE(p.CPE); ok && len(p.CVEs) > 0 {
+		// 与 vulners 脚本的输出格式一致
+		var b strings.Builder
+		b.WriteString("\n  " + cpe.URI() + ":")
+		for _, cve := range p.CVEs {
+			fmt.Fprintf(&b, "\n    \t%s\t%.1f\thttps://nvd.nist.gov/vuln/detail/%s", cve.ID, cve.CVSS, cve.ID)
+		}
+		port.Scripts = append(port.Scripts, nmapScript{ID: "vulners", Output: b.String()})
+	}
+	for _, finding := range p.Findings {
+		port.Scripts = append(port.Scripts, nmapScript{
+			ID:     "glideway-" + finding.ID,
+			Output: fmt.Sprintf("%s [%s]: %s", finding.Name, finding.Severity, finding.Evidence),
+		})
+	}
+	return port
+}
+
+// portRangeString 将端口列表压缩为 "1-1024,3306,8000-8100" 形式
+func portRangeString(ports []int) string {
+	sorted := append([]int(nil), ports...)
+	sort.Ints(sorted)
+
+	var parts []string
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		if i == j {
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		} else {
+			parts = append(parts, strconv.Itoa(sorted[i])+"-"+strconv.Itoa(sorted[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
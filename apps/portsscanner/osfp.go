@@ -0,0 +1,306 @@
+package portsscanner
+
+import (
+	"encoding/binary"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 每台主机保留的 SYN/ACK 样本数
+const maxOSSamples = 16
+
+// 推测结果低于该置信度时不给出操作系统
+const minOSConfidence = 40
+
+// HostOSInfo 根据 TCP/IP 协议栈特征推测的主机操作系统
+type HostOSInfo struct {
+	Host       string `json:"host"`
+	OS         string `json:"os"`          // 推测的操作系统，无法判断时为空
+	Confidence int    `json:"confidence"`  // 置信度 0-100
+	InitialTTL int    `json:"initial_ttl"` // 推算的初始 TTL
+	Distance   int    `json:"distance"`    // 推算的跳数
+	Window     int    `json:"window"`      // SYN/ACK 窗口大小
+	Options    string `json:"options"`     // SYN/ACK 中 TCP 选项的顺序，M=MSS N=NOP W=窗口扩大 S=SACK T=时间戳
+	DF         bool   `json:"df"`          // SYN/ACK 是否设置了不分片标志
+	SynAcks    int    `json:"syn_acks"`    // 用于推测的 SYN/ACK 样本数
+	Resets     int    `json:"resets"`      // 收到的 RST 数，多来自关闭的端口
+}
+
+type HostInfoCallback func(HostOSInfo)
+
+// tcpTraits 单个响应报文中可观测的协议栈特征
+type tcpTraits struct {
+	synAck  bool
+	ttl     int
+	df      bool
+	ipv6    bool // IPv6 没有 DF 标志，评分时不比较
+	window  int
+	options string
+}
+
+// osSignature 常见系统对带 MSS、SACK、时间戳与窗口扩大选项的 SYN 的响应特征
+type osSignature struct {
+	name    string
+	ttl     int
+	options []string
+	windows []int
+	df      bool
+}
+
+var osSignatures = []osSignature{
+	{"Linux", 64, []string{"M,S,T,N,W", "M,N,N,S,N,W", "M,S,N,W", "M,N,N,T,N,W"},
+		[]int{65160, 64240, 65483, 43690, 28960, 29200, 14480, 5792}, true},
+	{"FreeBSD", 64, []string{"M,N,W,S,T", "M,N,W,N,N,T", "M,N,W,S"},
+		[]int{65535, 65228}, true},
+	{"macOS / iOS", 64, []string{"M,N,W,N,N,T,S", "M,N,W,S"},
+		[]int{65535}, true},
+	{"Windows", 128, []string{"M,N,W,S,T", "M,N,W,N,N,S", "M,N,W,S", "M,N,W,N,N,T,N,N,S"},
+		[]int{8192, 65535, 64240, 64000, 16384}, true},
+	{"网络设备 (Cisco IOS 等)", 255, []string{"M", ""},
+		[]int{4128, 4096, 16384, 8192}, false},
+}
+
+// parseTCPTraits 从 IPv4 + TCP 报文中提取来源地址与协议栈特征，只接受 SYN/ACK 与 RST
+func parseTCPTraits(pkt []byte) (string, tcpTraits, bool) {
+	if len(pkt) < 20 || pkt[0]>>4 != 4 || pkt[9] != 6 {
+		return "", tcpTraits{}, false
+	}
+	ihl := int(pkt[0]&0x0f) * 4
+	if ihl < 20 || len(pkt) < ihl {
+		return "", tcpTraits{}, false
+	}
+	t, ok := parseTCPSegment(pkt[ihl:])
+	if !ok {
+		return "", tcpTraits{}, false
+	}
+	t.ttl = int(pkt[8])
+	t.df = pkt[6]&0x40 != 0
+	return net.IP(pkt[12:16]).String(), t, true
+}
+
+// parseTCPSegment 从 TCP 头部提取窗口与选项顺序，只接受 SYN/ACK 与 RST。
+// IPv6 原始套接字收到的报文不含 IP 头部，跳数限制由调用方从控制消息中取得
+func parseTCPSegment(tcp []byte) (tcpTraits, bool) {
+	if len(tcp) < 20 {
+		return tcpTraits{}, false
+	}
+	flags := tcp[13]
+	t := tcpTraits{window: int(binary.BigEndian.Uint16(tcp[14:16]))}
+	switch {
+	case flags&(tcpFlagSYN|tcpFlagACK) == tcpFlagSYN|tcpFlagACK:
+		t.synAck = true
+	case flags&tcpFlagRST != 0:
+	default:
+		return tcpTraits{}, false
+	}
+
+	offset := int(tcp[12]>>4) * 4
+	if offset > 20 && len(tcp) >= offset {
+		t.options = tcpOptionOrder(tcp[20:offset])
+	}
+	return t, true
+}
+
+// tcpOptionOrder 将 TCP 选项转换为顺序字符串，忽略末尾用于填充的 EOL
+func tcpOptionOrder(opts []byte) string {
+	var order []string
+	for i := 0; i < len(opts); {
+		kind := opts[i]
+		switch kind {
+		case 0:
+			return strings.Join(order, ",")
+		case 1:
+			order = append(order, "N")
+			i++
+			continue
+		}
+		if i+1 >= len(opts) || opts[i+1] < 2 {
+			// 选项长度无效
+			return strings.Join(order, ",")
+		}
+		switch kind {
+		case 2:
+			order = append(order, "M")
+		case 3:
+			order = append(order, "W")
+		case 4:
+			order = append(order, "S")
+		case 8:
+			order = append(order, "T")
+		default:
+			order = append(order, "?")
+		}
+		i += int(opts[i+1])
+	}
+	return strings.Join(order, ",")
+}
+
+// initialTTL 将观测到的 TTL 向上取整到常见的初始值
+func initialTTL(ttl int) int {
+	for _, v := range []int{32, 64, 128} {
+		if ttl <= v {
+			return v
+		}
+	}
+	return 255
+}
+
+// score 计算单个 SYN/ACK 样本与特征的吻合程度，满分 100
+func (sig osSignature) score(t tcpTraits) int {
+	score := 0
+	if initialTTL(t.ttl) == sig.ttl {
+		score += 40
+	}
+	for _, options := range sig.options {
+		if options == t.options {
+			score += 35
+			break
+		}
+		if sameOptionSet(options, t.options) {
+			score += 15
+			break
+		}
+	}
+	for _, window := range sig.windows {
+		if window == t.window {
+			score += 15
+			break
+		}
+	}
+	if t.ipv6 || sig.df == t.df {
+		score += 10
+	}
+	return score
+}
+
+func sameOptionSet(a, b string) bool {
+	x, y := strings.Split(a, ","), strings.Split(b, ",")
+	sort.Strings(x)
+	sort.Strings(y)
+	return strings.Join(x, ",") == strings.Join(y, ",")
+}
+
+// osObserver 按主机汇总扫描过程中收到的 SYN/ACK 特征，RST 只计数并记录首个的 TTL
+type osObserver struct {
+	mu       sync.Mutex
+	hosts    map[string]bool
+	samples  map[string][]tcpTraits
+	resets   map[string]int
+	resetTTL map[string]int
+}
+
+func newOSObserver(targets []Target) *osObserver {
+	o := &osObserver{
+		hosts:    make(map[string]bool, len(targets)),
+		samples:  make(map[string][]tcpTraits),
+		resets:   make(map[string]int),
+		resetTTL: make(map[string]int),
+	}
+	for _, t := range targets {
+		o.hosts[t.IP] = true
+	}
+	return o
+}
+
+// observe 记录一个响应，只接受扫描目标发来的报文
+func (o *osObserver) observe(host string, t tcpTraits) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.hosts[host] {
+		return
+	}
+	if !t.synAck {
+		if o.resets[host] == 0 {
+			o.resetTTL[host] = t.ttl
+		}
+		o.resets[host]++
+		return
+	}
+	if len(o.samples[host]) < maxOSSamples {
+		o.samples[host] = append(o.samples[host], t)
+	}
+}
+
+// guess 根据主机的全部样本推测操作系统，没有任何样本时返回 false。
+// 只有 RST 时仅能根据初始 TTL 判断，不给出具体系统
+func (o *osObserver) guess(host string) (HostOSInfo, bool) {
+	o.mu.Lock()
+	synAcks := append([]tcpTraits(nil), o.samples[host]...)
+	info := HostOSInfo{Host: host, Resets: o.resets[host]}
+	resetTTL := o.resetTTL[host]
+	o.mu.Unlock()
+
+	info.SynAcks = len(synAcks)
+	if len(synAcks) == 0 {
+		if info.Resets == 0 {
+			return info, false
+		}
+		info.InitialTTL = initialTTL(resetTTL)
+		info.Distance = info.InitialTTL - resetTTL
+		return info, true
+	}
+
+	first := synAcks[0]
+	info.InitialTTL = initialTTL(first.ttl)
+	info.Distance = info.InitialTTL - first.ttl
+	info.Window = first.window
+	info.Options = first.options
+	info.DF = first.df
+
+	best, bestScore := "", 0
+	for _, sig := range osSignatures {
+		total := 0
+		for _, t := range synAcks {
+			total += sig.score(t)
+		}
+		if score := total / len(synAcks); score > bestScore {
+			best, bestScore = sig.name, score
+		}
+	}
+	if bestScore >= minOSConfidence {
+		info.OS = best
+		info.Confidence = bestScore
+	}
+	return info, true
+}
+
+// osDetector 扫描期间的被动操作系统识别
+type osDetector struct {
+	observer *osObserver
+	sniffer  *osSniffer
+}
+
+// startOSDetect 设置了 HostInfo 回调时开始旁听目标的 TCP 响应。
+// 需要原始套接字权限，没有权限或为 UDP 扫描时返回 nil，扫描照常进行
+func (c *ScanConfig) startOSDetect(targets []Target) *osDetector {
+	if c.HostInfo == nil || c.ScanType == ScanTypeUDP {
+		return nil
+	}
+	observer := newOSObserver(targets)
+	sniffer, err := newOSSniffer(observer)
+	if err != nil {
+		return nil
+	}
+	return &osDetector{observer: observer, sniffer: sniffer}
+}
+
+func (d *osDetector) Close() {
+	if d != nil {
+		d.sniffer.Close()
+	}
+}
+
+// report 停止旁听并按目标顺序回调每台有响应的主机的推测结果
+func (d *osDetector) report(targets []Target, callback HostInfoCallback) {
+	if d == nil {
+		return
+	}
+	d.sniffer.Close()
+	for _, t := range targets {
+		if info, ok := d.observer.guess(t.IP); ok {
+			callback(info)
+		}
+	}
+}
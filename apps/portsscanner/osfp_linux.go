@@ -0,0 +1,148 @@
+//go:build linux
+
+package portsscanner
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// osSniffer 通过原始套接字旁听扫描目标返回的 SYN/ACK 与 RST，用于被动推测操作系统。
+// 原始 TCP 套接字会收到全部入站 TCP 报文的副本，不影响内核对连接的正常处理
+type osSniffer struct {
+	fd4      int
+	fd6      int // 系统不支持 IPv6 时为 -1，IPv6 目标不会得到推测结果
+	observer *osObserver
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+func newOSSniffer(observer *osObserver) (*osSniffer, error) {
+	fd4, err := openRawTCP(syscall.AF_INET)
+	if err != nil {
+		return nil, err
+	}
+	s := &osSniffer{
+		fd4:      fd4,
+		fd6:      -1,
+		observer: observer,
+		done:     make(chan struct{}),
+	}
+
+	// IPv6 原始套接字不返回 IP 头部，需要通过控制消息取得跳数限制
+	if fd6, err := openRawTCP(syscall.AF_INET6); err == nil {
+		if syscall.SetsockoptInt(fd6, syscall.IPPROTO_IPV6, syscall.IPV6_RECVHOPLIMIT, 1) == nil {
+			s.fd6 = fd6
+		} else {
+			syscall.Close(fd6)
+		}
+	}
+
+	s.wg.Add(1)
+	go s.receiveLoop4()
+	if s.fd6 >= 0 {
+		s.wg.Add(1)
+		go s.receiveLoop6()
+	}
+	return s, nil
+}
+
+// openRawTCP 创建原始 TCP 套接字，接收超时用于定期检查是否已关闭
+func openRawTCP(family int) (int, error) {
+	fd, err := syscall.Socket(family, syscall.SOCK_RAW, syscall.IPPROTO_TCP)
+	if err != nil {
+		if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES) {
+			return -1, fmt.Errorf("操作系统识别需要 root 或 CAP_NET_RAW 权限: %w", err)
+		}
+		return -1, fmt.Errorf("创建原始套接字失败: %w", err)
+	}
+
+	tv := syscall.NsecToTimeval(int64(200 * time.Millisecond))
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		syscall.Close(fd)
+		return -1, fmt.Errorf("设置原始套接字超时失败: %w", err)
+	}
+	return fd, nil
+}
+
+// Close 停止接收并等待接收循环退出后再关闭套接字，
+// 避免循环读取到被其他扫描复用的文件描述符
+func (s *osSniffer) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		syscall.Close(s.fd4)
+		if s.fd6 >= 0 {
+			syscall.Close(s.fd6)
+		}
+	})
+}
+
+func (s *osSniffer) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *osSniffer) receiveLoop4() {
+	defer s.wg.Done()
+	buf := make([]byte, 65535)
+	for !s.stopped() {
+		n, _, err := syscall.Recvfrom(s.fd4, buf, 0)
+		if err != nil {
+			continue
+		}
+		if host, traits, ok := parseTCPTraits(buf[:n]); ok {
+			s.observer.observe(host, traits)
+		}
+	}
+}
+
+func (s *osSniffer) receiveLoop6() {
+	defer s.wg.Done()
+	buf := make([]byte, 65535)
+	oob := make([]byte, syscall.CmsgSpace(4))
+	for !s.stopped() {
+		n, oobn, _, from, err := syscall.Recvmsg(s.fd6, buf, oob, 0)
+		if err != nil {
+			continue
+		}
+		addr, ok := from.(*syscall.SockaddrInet6)
+		if !ok {
+			continue
+		}
+		hopLimit, ok := parseHopLimit(oob[:oobn])
+		if !ok {
+			continue
+		}
+		if traits, ok := parseTCPSegment(buf[:n]); ok {
+			traits.ttl = hopLimit
+			traits.ipv6 = true
+			s.observer.observe(net.IP(addr.Addr[:]).String(), traits)
+		}
+	}
+}
+
+// parseHopLimit 从控制消息中取出 IPV6_HOPLIMIT
+func parseHopLimit(oob []byte) (int, bool) {
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return 0, false
+	}
+	for _, msg := range msgs {
+		if msg.Header.Level == syscall.IPPROTO_IPV6 && msg.Header.Type == syscall.IPV6_HOPLIMIT && len(msg.Data) >= 4 {
+			return int(binary.NativeEndian.Uint32(msg.Data)), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+
+package portsscanner
+
+import "fmt"
+
+// osSniffer 非 Linux 平台不支持通过原始套接字旁听响应
+type osSniffer struct{}
+
+func newOSSniffer(observer *osObserver) (*osSniffer, error) {
+	return nil, fmt.Errorf("操作系统识别仅支持 Linux")
+}
+
+func (s *osSniffer) Close() {}
@@ -0,0 +1,170 @@
+package portsscanner
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	minPort = 1
+	maxPort = 65535
+)
+
+// nmap --top-ports 1000 的端口，按 nmap-services 的 open-frequency 降序排列，
+// 与 nmap 一致，频率相同的端口按端口号排列
+//
+//go:embed portlists/top-1000.txt
+var top1000Spec string
+
+// topPorts 为按频率排序的 top-1000 列表，top-N 取前 N 个
+var topPorts = buildTopPorts()
+
+func buildTopPorts() []int {
+	ports, err := parsePortRanges(top1000Spec)
+	if err != nil {
+		panic(fmt.Sprintf("invalid embedded top-1000 list: %v", err))
+	}
+	return ports
+}
+
+// ParsePortSpec 解析 nmap 风格的端口表达式，返回去重后保持书写顺序的端口列表
+// 支持: 单端口(80)、列表(22,80,443)、范围(8000-8100、-1024、60000-)、
+// 全端口(all 或 -)、常用端口(top100、top1000、top-N)，以及 ! 前缀的排除项(!22、!135-139)
+func ParsePortSpec(spec string) ([]int, error) {
+	var include []int
+	exclude := make(map[int]bool)
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+
+		excluded := strings.HasPrefix(item, "!")
+		item = strings.TrimSpace(strings.TrimPrefix(item, "!"))
+
+		ports, err := parsePortItem(item)
+		if err != nil {
+			return nil, err
+		}
+
+		if excluded {
+			for _, p := range ports {
+				exclude[p] = true
+			}
+		} else {
+			include = append(include, ports...)
+		}
+	}
+
+	if len(include) == 0 && len(exclude) > 0 {
+		// 只有排除项时视为对全端口做排除
+		include, _ = parsePortItem("all")
+	}
+
+	result := make([]int, 0, len(include))
+	for _, p := range dedupPorts(include) {
+		if !exclude[p] {
+			result = append(result, p)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("端口表达式 %q 没有可扫描的端口", spec)
+	}
+	return result, nil
+}
+
+// TopPorts 返回按频率排序的前 n 个常用端口，n 最大为 1000
+func TopPorts(n int) []int {
+	if n <= 0 || n > len(topPorts) {
+		n = len(topPorts)
+	}
+	return append([]int(nil), topPorts[:n]...)
+}
+
+func parsePortItem(item string) ([]int, error) {
+	switch item {
+	case "all", "-":
+		return portRange(minPort, maxPort), nil
+	}
+
+	if strings.HasPrefix(item, "top") {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(item, "top"), "-"))
+		if err != nil || n <= 0 || n > len(topPorts) {
+			return nil, fmt.Errorf("无效的常用端口表达式: %s (支持 top1 ~ top%d)", item, len(topPorts))
+		}
+		return TopPorts(n), nil
+	}
+
+	return parsePortRanges(item)
+}
+
+// parsePortRanges 解析仅由端口和范围组成的表达式
+func parsePortRanges(spec string) ([]int, error) {
+	var ports []int
+	for _, item := range strings.Split(strings.TrimSpace(spec), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		idx := strings.Index(item, "-")
+		if idx < 0 {
+			p, err := parsePort(item)
+			if err != nil {
+				return nil, err
+			}
+			ports = append(ports, p)
+			continue
+		}
+
+		start, end := minPort, maxPort
+		var err error
+		if s := strings.TrimSpace(item[:idx]); s != "" {
+			if start, err = parsePort(s); err != nil {
+				return nil, err
+			}
+		}
+		if e := strings.TrimSpace(item[idx+1:]); e != "" {
+			if end, err = parsePort(e); err != nil {
+				return nil, err
+			}
+		}
+		if start > end {
+			return nil, fmt.Errorf("无效的端口范围: %s", item)
+		}
+		ports = append(ports, portRange(start, end)...)
+	}
+	return ports, nil
+}
+
+func parsePort(s string) (int, error) {
+	p, err := strconv.Atoi(s)
+	if err != nil || p < minPort || p > maxPort {
+		return 0, fmt.Errorf("无效的端口: %s (端口号必须在 %d-%d 之间)", s, minPort, maxPort)
+	}
+	return p, nil
+}
+
+func portRange(start, end int) []int {
+	ports := make([]int, 0, end-start+1)
+	for p := start; p <= end; p++ {
+		ports = append(ports, p)
+	}
+	return ports
+}
+
+func dedupPorts(ports []int) []int {
+	seen := make(map[int]bool, len(ports))
+	result := make([]int, 0, len(ports))
+	for _, p := range ports {
+		if !seen[p] {
+			seen[p] = true
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package portsscanner
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePortSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want []int
+	}{
+		{"80", []int{80}},
+		{" 22 , 80 ,443 ", []int{22, 80, 443}},
+		{"443,80,443,22", []int{443, 80, 22}},
+		{"8000-8003", []int{8000, 8001, 8002, 8003}},
+		{"-3", []int{1, 2, 3}},
+		{"65534-", []int{65534, 65535}},
+		{"1-5,!3", []int{1, 2, 4, 5}},
+		{"!135-139,130-140", []int{130, 131, 132, 133, 134, 140}},
+		{"top5", []int{80, 23, 443, 21, 22}},
+		{"TOP-3,8080", []int{80, 23, 443, 8080}},
+	}
+	for _, tt := range tests {
+		got, err := ParsePortSpec(tt.spec)
+		if err != nil {
+			t.Errorf("ParsePortSpec(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePortSpec(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestParsePortSpecCounts(t *testing.T) {
+	tests := []struct {
+		spec  string
+		count int
+	}{
+		{"all", 65535},
+		{"-", 65535},
+		{"!22", 65534},
+		{"!1-1024", 64511},
+		{"top100", 100},
+		{"top1000", 1000},
+		{"top1000,!80", 999},
+	}
+	for _, tt := range tests {
+		got, err := ParsePortSpec(tt.spec)
+		if err != nil {
+			t.Errorf("ParsePortSpec(%q) error: %v", tt.spec, err)
+			continue
+		}
+		if len(got) != tt.count {
+			t.Errorf("ParsePortSpec(%q) returned %d ports, want %d", tt.spec, len(got), tt.count)
+		}
+	}
+}
+
+func TestParsePortSpecErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"0",
+		"65536",
+		"http",
+		"100-10",
+		"top0",
+		"top1001",
+		"topx",
+		"22,!22",
+		"!1-65535",
+	} {
+		if got, err := ParsePortSpec(spec); err == nil {
+			t.Errorf("ParsePortSpec(%q) = %v, want error", spec, got)
+		}
+	}
+}
+
+func TestTopPortsList(t *testing.T) {
+	seen := make(map[int]bool)
+	for _, port := range topPorts {
+		if port < minPort || port > maxPort || seen[port] {
+			t.Fatalf("top-1000 list has invalid or duplicate port %d", port)
+		}
+		seen[port] = true
+	}
+	if len(topPorts) != 1000 {
+		t.Fatalf("top-1000 list has %d ports", len(topPorts))
+	}
+}
@@ -0,0 +1,484 @@
+package portsscanner
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lcvvvv/gonmap"
+)
+
+// 内置的 nmap-service-probes 探针库，取自 gonmap 并已修正为 Go 正则兼容格式，
+// 版权与许可见同目录下的 NOTICE
+//
+//go:embed nmap-service-probes
+var builtinServiceProbes string
+
+// serviceProbe 对应 nmap-service-probes 中的一个 Probe 段
+type serviceProbe struct {
+	protocol string // TCP / UDP
+	name     string // 协议前缀加探针名，如 UDP_DNSStatusRequest，与 gonmap 的命名一致
+	payload  []byte
+	ports    []int
+	sslports []int
+	rarity   int
+	fallback []string
+	matches  []*serviceMatch
+}
+
+type serviceMatch struct {
+	soft        bool
+	service     string
+	re          *regexp.Regexp
+	versionInfo map[string]string // p v i h o d 以及 cpe
+}
+
+// probeDB 探针库，probes 按 rarity 升序
+type probeDB struct {
+	probes []*serviceProbe
+	byName map[string]*serviceProbe
+}
+
+// ProbeParseError 探针文件解析错误
+type ProbeParseError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+func (e ProbeParseError) Error() string {
+	return fmt.Sprintf("第 %d 行: %s", e.Line, e.Message)
+}
+
+// builtinProbes 按协议(TCP / UDP)划分的内置探针库，初始化时解析，嵌入的文件有误时直接 panic
+var builtinProbes = buildBuiltinProbes()
+
+func buildBuiltinProbes() map[string]*probeDB {
+	all, errs := parseServiceProbes(builtinServiceProbes, "")
+	if len(errs) > 0 {
+		panic(fmt.Sprintf("invalid embedded nmap-service-probes: %v", errs[0]))
+	}
+
+	dbs := map[string]*probeDB{
+		"TCP": {byName: make(map[string]*serviceProbe)},
+		"UDP": {byName: make(map[string]*serviceProbe)},
+	}
+	for _, p := range all.probes {
+		dbs[p.protocol].add(p)
+	}
+	return dbs
+}
+
+// builtinProbeDB 返回内置探针库中指定协议(TCP / UDP)的探针
+func builtinProbeDB(protocol string) *probeDB {
+	if protocol == "UDP" {
+		return builtinProbes["UDP"]
+	}
+	return builtinProbes["TCP"]
+}
+
+// tcpProbeDB 返回内置与自定义的全部 TCP 探针，用于首次连接时的 Banner 匹配
+func tcpProbeDB() *probeDB {
+	return withCustomProbes("TCP")
+}
+
+// udpProbeDB 返回内置与自定义的全部 UDP 探针
+func udpProbeDB() *probeDB {
+	return withCustomProbes("UDP")
+}
+
+// parseServiceProbes 解析 nmap-service-probes 格式文本，protocol 非空时只保留对应协议的探针。
+// 无法解析的行会被跳过并记录在返回的错误列表中
+func parseServiceProbes(text string, protocol string) (*probeDB, []ProbeParseError) {
+	db := &probeDB{byName: make(map[string]*serviceProbe)}
+	var errs []ProbeParseError
+	var cur *serviceProbe
+
+	for i, line := range strings.Split(text, "\n") {
+		lineNo := i + 1
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		directive, args, _ := strings.Cut(line, " ")
+		if directive == "Probe" {
+			cur = nil
+			p, err := parseProbeLine(args)
+			if err != nil {
+				errs = append(errs, ProbeParseError{Line: lineNo, Message: err.Error()})
+				continue
+			}
+			if protocol != "" && p.protocol != protocol {
+				continue
+			}
+			cur = p
+			db.add(p)
+			continue
+		}
+
+		if directive == "Exclude" {
+			continue
+		}
+		if cur == nil {
+			if protocol == "" && directive != "" {
+				errs = append(errs, ProbeParseError{Line: lineNo, Message: fmt.Sprintf("%s 指令之前缺少 Probe 定义", directive)})
+			}
+			continue
+		}
+
+		if err := cur.loadDirective(directive, args); err != nil {
+			errs = append(errs, ProbeParseError{Line: lineNo, Message: err.Error()})
+		}
+	}
+
+	sort.SliceStable(db.probes, func(i, j int) bool {
+		return db.probes[i].rarity < db.probes[j].rarity
+	})
+	return db, errs
+}
+
+func (db *probeDB) add(p *serviceProbe) {
+	if old, ok := db.byName[p.name]; ok {
+		// 同名探针合并指纹，便于在内置探针上追加规则
+		old.matches = append(old.matches, p.matches...)
+		return
+	}
+	db.byName[p.name] = p
+	db.probes = append(db.probes, p)
+}
+
+// probesForPort 返回适用于指定端口的探针，按 rarity 升序
+func (db *probeDB) probesForPort(port int) []*serviceProbe {
+	var result []*serviceProbe
+	for _, p := range db.probes {
+		if containsPort(p.ports, port) || containsPort(p.sslports, port) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// match 使用探针自身及其 fallback 的指纹匹配响应
+func (db *probeDB) match(p *serviceProbe, response []byte) *gonmap.FingerPrint {
+	data := bytesToLatin1(response)
+
+	fp := p.match(data)
+	for _, name := range p.fallback {
+		if fp.Service != "" {
+			break
+		}
+		for _, candidate := range []string{name, p.protocol + "_" + name} {
+			if fb, ok := db.byName[candidate]; ok {
+				fp = fb.match(data)
+				break
+			}
+		}
+	}
+	fp.ProbeName = p.name
+	return fp
+}
+
+func (p *serviceProbe) match(data string) *gonmap.FingerPrint {
+	fp, _ := p.find(data)
+	return fp
+}
+
+// find 依次尝试探针下的匹配规则，hard 表示命中了带版本信息的 match 而非 softmatch
+func (p *serviceProbe) find(data string) (fp *gonmap.FingerPrint, hard bool) {
+	fp = &gonmap.FingerPrint{}
+	softService := ""
+
+	for _, m := range p.matches {
+		if softService != "" && m.service != softService {
+			continue
+		}
+		groups := m.re.FindStringSubmatch(data)
+		if groups == nil {
+			continue
+		}
+
+		fp.MatchRegexString = m.re.String()
+		fp.Service = m.service
+		if m.soft {
+			softService = m.service
+			continue
+		}
+
+		fp.ProductName = expandVersionInfo(m.versionInfo["p"], groups)
+		fp.Version = expandVersionInfo(m.versionInfo["v"], groups)
+		fp.Info = expandVersionInfo(m.versionInfo["i"], groups)
+		fp.Hostname = expandVersionInfo(m.versionInfo["h"], groups)
+		fp.OperatingSystem = expandVersionInfo(m.versionInfo["o"], groups)
+		fp.DeviceType = expandVersionInfo(m.versionInfo["d"], groups)
+		return fp, true
+	}
+	return fp, false
+}
+
+func (p *serviceProbe) loadDirective(directive, args string) error {
+	switch directive {
+	case "match", "softmatch":
+		m, err := parseMatchLine(args, directive == "softmatch")
+		if err != nil {
+			return err
+		}
+		p.matches = append(p.matches, m)
+	case "ports", "sslports":
+		ports, err := parsePortRanges(args)
+		if err != nil {
+			return err
+		}
+		if directive == "ports" {
+			p.ports = ports
+		} else {
+			p.sslports = ports
+		}
+	case "rarity":
+		rarity, err := strconv.Atoi(strings.TrimSpace(args))
+		if err != nil {
+			return fmt.Errorf("无效的 rarity: %s", args)
+		}
+		p.rarity = rarity
+	case "fallback":
+		for _, name := range strings.Split(args, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				p.fallback = append(p.fallback, name)
+			}
+		}
+	case "totalwaitms", "tcpwrappedms":
+		// 超时由扫描配置统一控制
+	default:
+		return fmt.Errorf("未知指令: %s", directive)
+	}
+	return nil
+}
+
+// parseProbeLine 解析 "Probe <TCP|UDP> <name> q|<payload>|"
+func parseProbeLine(args string) (*serviceProbe, error) {
+	fields := strings.SplitN(args, " ", 3)
+	if len(fields) != 3 || (fields[0] != "TCP" && fields[0] != "UDP") {
+		return nil, fmt.Errorf("Probe 语句格式不正确: %s", args)
+	}
+
+	body := strings.TrimSpace(fields[2])
+	if len(body) < 3 || body[0] != 'q' {
+		return nil, fmt.Errorf("Probe %s 缺少 q 载荷", fields[1])
+	}
+	delim := body[1]
+	end := strings.IndexByte(body[2:], delim)
+	if end < 0 {
+		return nil, fmt.Errorf("Probe %s 载荷未闭合", fields[1])
+	}
+
+	payload, err := unescapeProbeString(body[2 : 2+end])
+	if err != nil {
+		return nil, fmt.Errorf("Probe %s 载荷无效: %w", fields[1], err)
+	}
+
+	return &serviceProbe{
+		protocol: fields[0],
+		name:     fields[0] + "_" + fields[1],
+		payload:  payload,
+		rarity:   1,
+	}, nil
+}
+
+// parseMatchLine 解析 "<service> m<d><pattern><d>[opts] [versioninfo]"
+func parseMatchLine(args string, soft bool) (*serviceMatch, error) {
+	service, rest, ok := strings.Cut(args, " ")
+	if !ok || len(rest) < 3 || rest[0] != 'm' {
+		return nil, fmt.Errorf("match 语句格式不正确: %s", args)
+	}
+
+	delim := rest[1]
+	end := strings.IndexByte(rest[2:], delim)
+	if end < 0 {
+		return nil, fmt.Errorf("match %s 的正则未闭合", service)
+	}
+	pattern := rest[2 : 2+end]
+	rest = rest[2+end+1:]
+
+	opts := ""
+	for len(rest) > 0 && (rest[0] == 'i' || rest[0] == 's') {
+		opts += rest[:1]
+		rest = rest[1:]
+	}
+
+	re, err := compileProbePattern(pattern, opts)
+	if err != nil {
+		return nil, fmt.Errorf("match %s 的正则无法编译: %v", service, err)
+	}
+
+	return &serviceMatch{
+		soft:        soft,
+		service:     gonmap.FixProtocol(service),
+		re:          re,
+		versionInfo: parseVersionInfo(rest),
+	}, nil
+}
+
+func compileProbePattern(pattern, opts string) (*regexp.Regexp, error) {
+	pattern = strings.ReplaceAll(pattern, `\0`, `\x00`)
+	if opts != "" {
+		pattern = "(?" + opts + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// parseVersionInfo 解析 p/.../ v/.../ i/.../ h/.../ o/.../ d/.../ cpe:/.../ 字段，分隔符可为任意字符
+func parseVersionInfo(s string) map[string]string {
+	info := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			return info
+		}
+
+		key := s[:1]
+		s = s[1:]
+		if key == "c" && strings.HasPrefix(s, "pe:") {
+			key = "cpe"
+			s = s[3:]
+		}
+		if s == "" {
+			return info
+		}
+
+		delim := s[0]
+		end := strings.IndexByte(s[1:], delim)
+		if end < 0 {
+			return info
+		}
+		value := s[1 : 1+end]
+		s = strings.TrimLeft(s[1+end+1:], "ai")
+
+		if key == "cpe" {
+			if info[key] != "" {
+				value = info[key] + " " + value
+			}
+		}
+		info[key] = value
+	}
+}
+
+var versionHelperRegexp = regexp.MustCompile(`\$P\((\d)\)|\$SUBST\((\d),"([^"]*)","([^"]*)"\)|\$I\((\d),"([<>])"\)|\$(\d)`)
+
+// expandVersionInfo 将 $1、$P(1)、$SUBST(1,"a","b")、$I(1,">") 替换为正则捕获组内容
+func expandVersionInfo(tmpl string, groups []string) string {
+	if tmpl == "" {
+		return ""
+	}
+	group := func(s string) string {
+		i, _ := strconv.Atoi(s)
+		if i < len(groups) {
+			return groups[i]
+		}
+		return ""
+	}
+
+	out := versionHelperRegexp.ReplaceAllStringFunc(tmpl, func(expr string) string {
+		sub := versionHelperRegexp.FindStringSubmatch(expr)
+		switch {
+		case sub[1] != "":
+			return printable(group(sub[1]))
+		case sub[2] != "":
+			return strings.ReplaceAll(group(sub[2]), sub[3], sub[4])
+		case sub[5] != "":
+			return unpackInt(group(sub[5]), sub[6] == "<")
+		default:
+			return group(sub[7])
+		}
+	})
+	return strings.NewReplacer("\r", "", "\n", "").Replace(out)
+}
+
+func printable(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7f {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func unpackInt(s string, littleEndian bool) string {
+	var n uint64
+	runes := []rune(s)
+	for i := range runes {
+		r := runes[i]
+		if littleEndian {
+			r = runes[len(runes)-1-i]
+		}
+		n = n<<8 | uint64(r&0xff)
+	}
+	return strconv.FormatUint(n, 10)
+}
+
+// unescapeProbeString 处理探针载荷中的 C 风格转义
+func unescapeProbeString(s string) ([]byte, error) {
+	var out []byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			out = append(out, c)
+			continue
+		}
+		if i+1 >= len(s) {
+			return nil, fmt.Errorf("末尾存在未完成的转义")
+		}
+		i++
+		switch s[i] {
+		case '0':
+			out = append(out, 0)
+		case 'a':
+			out = append(out, '\a')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'v':
+			out = append(out, '\v')
+		case 'x':
+			if i+2 >= len(s) {
+				return nil, fmt.Errorf("不完整的 \\x 转义")
+			}
+			v, err := strconv.ParseUint(s[i+1:i+3], 16, 8)
+			if err != nil {
+				return nil, fmt.Errorf("无效的 \\x 转义: %s", s[i-1:i+3])
+			}
+			out = append(out, byte(v))
+			i += 2
+		default:
+			out = append(out, s[i])
+		}
+	}
+	return out, nil
+}
+
+// bytesToLatin1 将字节逐个映射为 rune，使 \xNN 形式的正则可以匹配二进制响应
+func bytesToLatin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func containsPort(ports []int, port int) bool {
+	for _, p := range ports {
+		if p == port {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package portsscanner
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 默认每秒上报 4 次进度
+const defaultProgressInterval = 250 * time.Millisecond
+
+type ProgressCallback func(ScanProgress)
+
+// progressTracker 汇总扫描进度，由定时器按固定间隔上报，避免逐端口推送事件
+type progressTracker struct {
+	total    int32
+	scanned  int32
+	resumed  int32 // 从断点继续时已完成的端口数，不计入扫描速率
+	stats    *ScanStats
+	start    time.Time
+	callback ProgressCallback
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newProgressTracker(total int, stats *ScanStats, interval time.Duration, callback ProgressCallback) *progressTracker {
+	t := &progressTracker{
+		total:    int32(total),
+		stats:    stats,
+		start:    time.Now(),
+		callback: callback,
+		done:     make(chan struct{}),
+	}
+	if callback == nil {
+		return t
+	}
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.callback(t.snapshot("scanning"))
+			case <-t.done:
+				return
+			}
+		}
+	}()
+	return t
+}
+
+// resume 以断点中已完成的端口数为起点
+func (t *progressTracker) resume(scanned int) {
+	atomic.StoreInt32(&t.scanned, int32(scanned))
+	atomic.StoreInt32(&t.resumed, int32(scanned))
+}
+
+// add 记录一个已完成的端口
+func (t *progressTracker) add() {
+	atomic.AddInt32(&t.scanned, 1)
+}
+
+// snapshot 计算当前的扫描速率与预计剩余时间
+func (t *progressTracker) snapshot(status string) ScanProgress {
+	scanned := atomic.LoadInt32(&t.scanned)
+	progress := ScanProgress{
+		Scanned:    scanned,
+		TotalPorts: t.total,
+		Open:       atomic.LoadInt32(&t.stats.Open) + atomic.LoadInt32(&t.stats.OpenFiltered),
+		Status:     status,
+	}
+
+	if elapsed := time.Since(t.start).Seconds(); elapsed > 0 {
+		progress.Rate = float64(scanned-atomic.LoadInt32(&t.resumed)) / elapsed
+	}
+	if progress.Rate > 0 {
+		progress.ETA = float64(t.total-scanned) / progress.Rate
+	}
+	return progress
+}
+
+// stop 停止定时上报并发送最终进度
+func (t *progressTracker) stop(status string) {
+	close(t.done)
+	t.wg.Wait()
+	if t.callback != nil {
+		t.callback(t.snapshot(status))
+	}
+}
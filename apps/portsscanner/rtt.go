@@ -0,0 +1,102 @@
+package portsscanner
+
+import (
+	"sync"
+	"time"
+)
+
+// 超时上下限与重传次数的默认值
+const (
+	defaultMinTimeout = 100 * time.Millisecond
+	defaultMaxTimeout = 3 * time.Second
+	defaultMaxRetries = 1
+)
+
+// rttEstimator 按 RFC 6298 (nmap 同样采用) 估算单个主机的往返时延，
+// 超时时间取 srtt + 4*rttvar 并限制在 [min, max] 之间
+type rttEstimator struct {
+	mu      sync.Mutex
+	srtt    time.Duration
+	rttvar  time.Duration
+	samples int
+
+	initial  time.Duration
+	min, max time.Duration
+}
+
+func newRTTEstimator(initial, min, max time.Duration) *rttEstimator {
+	return &rttEstimator{initial: initial, min: min, max: max}
+}
+
+// Update 记录一次成功的往返时延采样
+func (e *rttEstimator) Update(sample time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.samples == 0 {
+		e.srtt = sample
+		e.rttvar = sample / 2
+	} else {
+		delta := e.srtt - sample
+		if delta < 0 {
+			delta = -delta
+		}
+		e.rttvar = (3*e.rttvar + delta) / 4
+		e.srtt = (7*e.srtt + sample) / 8
+	}
+	e.samples++
+}
+
+// Timeout 返回当前应使用的探测超时
+func (e *rttEstimator) Timeout() time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	timeout := e.initial
+	if e.samples > 0 {
+		timeout = e.srtt + 4*e.rttvar
+	}
+	if timeout < e.min {
+		timeout = e.min
+	}
+	if timeout > e.max {
+		timeout = e.max
+	}
+	return timeout
+}
+
+// hostTimings 按主机维护 RTT 估算器
+type hostTimings struct {
+	mu       sync.Mutex
+	hosts    map[string]*rttEstimator
+	initial  time.Duration
+	min, max time.Duration
+}
+
+func newHostTimings(initial, min, max time.Duration) *hostTimings {
+	return &hostTimings{
+		hosts:   make(map[string]*rttEstimator),
+		initial: initial,
+		min:     min,
+		max:     max,
+	}
+}
+
+func (t *hostTimings) get(host string) *rttEstimator {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.hosts[host]
+	if !ok {
+		e = newRTTEstimator(t.initial, t.min, t.max)
+		t.hosts[host] = e
+	}
+	return e
+}
+
+// seed 使用主机发现阶段测得的时延作为初始采样
+func (t *hostTimings) seed(host string, rtt time.Duration) {
+	if rtt > 0 {
+		t.get(host).Update(rtt)
+	}
+}
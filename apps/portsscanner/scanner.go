@@ -2,61 +2,231 @@ package portsscanner
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
 	"sync/atomic"
 	"time"
+)
 
-	"github.com/lcvvvv/gonmap"
+// 扫描方式
+const (
+	ScanTypeConnect = "connect" // TCP 全连接扫描
+	ScanTypeSYN     = "syn"     // TCP 半连接扫描，需要原始套接字权限
+	ScanTypeUDP     = "udp"     // UDP 探针扫描
 )
 
 type ScanConfig struct {
-	Target     string
-	StartPort  int
-	EndPort    int
+	ScanType   string // 扫描方式，默认为 ScanTypeConnect
+	Target     string // 目标表达式: IP、CIDR、范围、逗号列表或域名
+	TargetFile string // 目标文件路径，每行一个目标表达式
+	Targets    []Target
+	IPPrefer   string // 域名解析的协议族偏好: IPPreferDual / IPPreferV4 / IPPreferV6
+	PortSpec   string // 端口表达式，见 ParsePortSpec
+	Ports      []int
 	MaxThreads int
-	Timeout    time.Duration
+	Timeout    time.Duration // 服务指纹识别超时，同时作为尚无 RTT 采样时的初始探测超时
+	MinTimeout time.Duration // 端口探测超时下限，默认 100ms
+	MaxTimeout time.Duration // 端口探测超时上限，默认 3s
+	MaxRetries int           // 探测超时(疑似被过滤)时的重传次数，默认 1，小于 0 表示不重传
+
+	// 排除的主机(IP、CIDR、范围、域名)与端口，ExcludeFile 为可选的排除主机文件，格式与目标文件相同。
+	// Excludes 为解析后的排除列表，为空时按以上字段解析
+	ExcludeHosts string
+	ExcludeFile  string
+	ExcludePorts string
+	Excludes     *ExcludeList
+
+	// 默认只上报 open 与 open|filtered 端口，防火墙分析时可同时上报 closed / filtered 端口
+	ReportClosed   bool
+	ReportFiltered bool
+
+	// 对识别出的服务执行只读的未授权访问检查
+	CheckUnauth bool
+
+	// 本地 CVE 库，为空时只生成 CPE 不匹配漏洞
+	CVEDB *CVEDatabase
+
+	// 扫描断点，不为空时跳过已完成的主机/端口并记录新的结果
+	Checkpoint *ScanCheckpoint
+
+	// 进度回调与上报间隔(默认 250ms)，与端口结果回调相互独立
+	Progress         ProgressCallback
+	ProgressInterval time.Duration
+
+	// 扫描完成后回调每台主机根据 TCP/IP 特征推测的操作系统，为空时不做识别。需要原始套接字权限(Linux)
+	HostInfo HostInfoCallback
+
+	// 不影响扫描继续进行的问题，如 SYN 扫描不可用时回退到全连接扫描，可为空
+	Warning func(message string)
+}
+
+// ResolveTargets 展开 Target 与 TargetFile 中的全部目标
+func (c *ScanConfig) ResolveTargets(ctx context.Context) ([]Target, error) {
+	expr := c.Target
+	if c.TargetFile != "" {
+		fileExpr, err := LoadTargetFile(c.TargetFile)
+		if err != nil {
+			return nil, err
+		}
+		expr = expr + "," + fileExpr
+	}
+
+	targets, err := ParseTargets(ctx, expr, c.IPPrefer)
+	if err != nil {
+		return nil, err
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("没有可扫描的目标")
+	}
+	return targets, nil
+}
+
+// ResolveExcludes 解析 ExcludeHosts、ExcludeFile 与 ExcludePorts 得到排除列表
+func (c *ScanConfig) ResolveExcludes(ctx context.Context) (*ExcludeList, error) {
+	if c.Excludes != nil {
+		return c.Excludes, nil
+	}
+	hosts := c.ExcludeHosts
+	if c.ExcludeFile != "" {
+		fileExpr, err := LoadTargetFile(c.ExcludeFile)
+		if err != nil {
+			return nil, err
+		}
+		hosts = hosts + "," + fileExpr
+	}
+	return ParseExcludeList(ctx, hosts, c.ExcludePorts)
+}
+
+// ResolvePorts 解析 PortSpec 得到待扫描的端口列表
+func (c *ScanConfig) ResolvePorts() ([]int, error) {
+	return ParsePortSpec(c.PortSpec)
 }
 
 type PortInfo struct {
-	Port            int    `json:"port"`
-	Protocol        string `json:"protocol"`
-	Service         string `json:"service"`
-	ProductName     string `json:"product_name"`
-	Version         string `json:"version"`
-	Info            string `json:"info"`
-	Hostname        string `json:"hostname"`
-	OperatingSystem string `json:"operating_system"`
-	DeviceType      string `json:"device_type"`
-	ProbeName       string `json:"probe_name"`
-	TLS             bool   `json:"tls"`
+	Host            string         `json:"host"`
+	Port            int            `json:"port"`
+	Protocol        string         `json:"protocol"`
+	State           string         `json:"state"`
+	Service         string         `json:"service"`
+	ProductName     string         `json:"product_name"`
+	Version         string         `json:"version"`
+	Info            string         `json:"info"`
+	Hostname        string         `json:"hostname"`
+	OperatingSystem string         `json:"operating_system"`
+	DeviceType      string         `json:"device_type"`
+	ProbeName       string         `json:"probe_name"`
+	TLS             bool           `json:"tls"`
+	Banner          string         `json:"banner,omitempty"`      // 服务端返回的原始数据(已转义)
+	Certificate     *TLSInfo       `json:"certificate,omitempty"` // TLS 端口的证书与握手信息
+	HTTP            *HTTPInfo      `json:"http,omitempty"`        // Web 端口的首页信息
+	Details         ServiceDetails `json:"details,omitempty"`     // SSH、SMB、RDP 等服务特有的元数据
+	CPE             string         `json:"cpe,omitempty"`         // 由产品名与版本归一化得到的 CPE 2.3
+	CVEs            []CVEMatch     `json:"cves,omitempty"`        // 本地 CVE 库中命中的漏洞
+	Findings        []Finding      `json:"findings,omitempty"`    // 未授权访问等安全问题
 }
 
 type PortCallback func(PortInfo)
 
-func ScanPortsCombined(ctx context.Context, config ScanConfig, callback PortCallback) error {
+// ScanStats 各状态端口的数量统计，包含未上报的 closed / filtered 端口
+type ScanStats struct {
+	Open         int32 `json:"open"`
+	Closed       int32 `json:"closed"`
+	Filtered     int32 `json:"filtered"`
+	OpenFiltered int32 `json:"open_filtered"`
+	Excluded     int32 `json:"excluded"` // 命中排除列表而跳过的主机:端口组合
+}
+
+func (s *ScanStats) add(state string) {
+	switch state {
+	case StateOpen:
+		atomic.AddInt32(&s.Open, 1)
+	case StateClosed:
+		atomic.AddInt32(&s.Closed, 1)
+	case StateFiltered:
+		atomic.AddInt32(&s.Filtered, 1)
+	case StateOpenFiltered:
+		atomic.AddInt32(&s.OpenFiltered, 1)
+	}
+}
+
+// ScanPortsCombined 扫描全部目标端口，按 config 的上报设置回调端口信息，返回各状态端口的统计
+func ScanPortsCombined(ctx context.Context, config ScanConfig, callback PortCallback) (*ScanStats, error) {
 	if callback == nil {
-		return fmt.Errorf("callback function cannot be nil")
+		return nil, fmt.Errorf("callback function cannot be nil")
+	}
+
+	if config.MaxThreads <= 0 {
+		return nil, fmt.Errorf("线程数必须大于0")
+	}
+
+	scanner, err := newPortScanner(config)
+	if err != nil {
+		return nil, err
 	}
+	defer scanner.Close()
+
+	targets := config.Targets
+	if len(targets) == 0 {
+		if targets, err = config.ResolveTargets(ctx); err != nil {
+			return nil, err
+		}
+	}
+	// 跳过主机发现时仍需在链路上展开 IPv6 网段
+	if targets, err = ExpandLinkTargets(ctx, targets); err != nil {
+		return nil, err
+	}
+
+	ports := config.Ports
+	if len(ports) == 0 {
+		if ports, err = config.ResolvePorts(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 排除的主机与端口在探测前去掉
+	excludes, err := config.ResolveExcludes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	targets, ports, excluded := excludes.Filter(targets, ports)
+
+	for _, target := range targets {
+		scanner.timings.seed(target.IP, target.RTT)
+		if target.Domain != "" {
+			scanner.domains[target.IP] = target.Domain
+		}
+	}
+
+	detector := config.startOSDetect(targets)
+	defer detector.Close()
 
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, config.MaxThreads)
-	var scanned int32
+	stats, tracker := config.newTracker(len(targets) * len(ports))
+	atomic.AddInt32(&stats.Excluded, int32(excluded))
 
-	// 创建gonmap实例
-	scanner := gonmap.New()
-	scanner.SetTimeout(config.Timeout)
+	for ti, target := range targets {
+		for pi, port := range ports {
+			index := ti*len(ports) + pi
+			if config.Checkpoint != nil && config.Checkpoint.isDone(index) {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				tracker.stop("cancelled")
+				return stats, context.Canceled
+			default:
+			}
 
-	for port := config.StartPort; port <= config.EndPort; port++ {
-		select {
-		case <-ctx.Done():
-			return context.Canceled
-		default:
 			wg.Add(1)
 			semaphore <- struct{}{}
 
-			go func(p int) {
+			go func(host string, p int) {
 				defer func() {
 					wg.Done()
 					<-semaphore
@@ -65,56 +235,291 @@ func ScanPortsCombined(ctx context.Context, config ScanConfig, callback PortCall
 					}
 				}()
 
-				// 更新进度
-				atomic.AddInt32(&scanned, 1)
-				select {
-				case <-ctx.Done():
+				portInfo := scanner.scanPort(ctx, host, p)
+				if portInfo.State == "" {
+					// 扫描已取消
 					return
-				default:
-					callback(PortInfo{
-						Port:     p,
-						Protocol: "progress",
-					})
 				}
+				config.finishPort(ctx, index, portInfo, stats, tracker, callback)
+			}(target.IP, port)
+		}
+	}
 
-				address := fmt.Sprintf("%s:%d", config.Target, p)
-				conn, err := net.DialTimeout("tcp", address, config.Timeout)
+	wg.Wait()
+	if ctx.Err() != nil {
+		tracker.stop("cancelled")
+		return stats, context.Canceled
+	}
+	tracker.stop("completed")
+	detector.report(targets, config.HostInfo)
+	return stats, nil
+}
 
-				if err == nil && conn != nil {
-					conn.Close()
+// newTracker 创建统计与进度跟踪，从断点继续时以断点中的进度为起点
+func (c *ScanConfig) newTracker(total int) (*ScanStats, *progressTracker) {
+	stats := &ScanStats{}
+	scanned := 0
+	if c.Checkpoint != nil {
+		scanned, *stats = c.Checkpoint.progress()
+	}
+	tracker := newProgressTracker(total, stats, c.ProgressInterval, c.Progress)
+	tracker.resume(scanned)
+	return stats, tracker
+}
 
-					// 对开放端口进行指纹识别
-					status, response := scanner.ScanTimeout(config.Target, p, config.Timeout)
+// finishPort 统计一个已完成的端口，按上报设置回调，并记录到断点
+func (c *ScanConfig) finishPort(ctx context.Context, index int, portInfo PortInfo, stats *ScanStats, tracker *progressTracker, callback PortCallback) {
+	stats.add(portInfo.State)
+	tracker.add()
+	reported := c.shouldReport(portInfo.State)
+	if reported {
+		select {
+		case <-ctx.Done():
+		default:
+			callback(portInfo)
+		}
+	}
+	// 回调之后再标记完成，避免断点中出现已完成但结果丢失的端口
+	if c.Checkpoint != nil {
+		c.Checkpoint.markDone(index, portInfo, reported)
+	}
+}
 
-					portInfo := PortInfo{
-						Port:     p,
-						Protocol: "tcp",
-					}
+// shouldReport 判断该状态的端口是否需要回调
+func (c *ScanConfig) shouldReport(state string) bool {
+	switch state {
+	case StateOpen, StateOpenFiltered:
+		return true
+	case StateClosed:
+		return c.ReportClosed
+	case StateFiltered:
+		return c.ReportFiltered
+	}
+	return false
+}
 
-					if status == gonmap.Matched && response != nil {
-						fp := response.FingerPrint
-						portInfo.Service = fp.Service
-						portInfo.ProductName = fp.ProductName
-						portInfo.Version = fp.Version
-						portInfo.Info = fp.Info
-						portInfo.Hostname = fp.Hostname
-						portInfo.OperatingSystem = fp.OperatingSystem
-						portInfo.DeviceType = fp.DeviceType
-						portInfo.ProbeName = fp.ProbeName
-						portInfo.TLS = response.TLS
-					}
+// portScanner 单次扫描的执行上下文，持有各主机的 RTT 估算与 SYN 扫描器
+type portScanner struct {
+	config   ScanConfig
+	timings  *hostTimings
+	syn      *synScanner
+	domains  map[string]string // IP -> 域名，用于 TLS SNI 与证书主机名校验
+	attempts int               // 每个端口的最多探测次数，至少为 1
+}
 
-					select {
-					case <-ctx.Done():
-						return
-					default:
-						callback(portInfo)
-					}
-				}
-			}(port)
+func newPortScanner(config ScanConfig) (*portScanner, error) {
+	if config.MinTimeout <= 0 {
+		config.MinTimeout = defaultMinTimeout
+	}
+	if config.MaxTimeout <= 0 {
+		config.MaxTimeout = defaultMaxTimeout
+	}
+	if config.MinTimeout > config.MaxTimeout {
+		return nil, fmt.Errorf("最小超时不能大于最大超时")
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = defaultMaxRetries
+	}
+
+	s := &portScanner{
+		config:   config,
+		timings:  newHostTimings(config.Timeout, config.MinTimeout, config.MaxTimeout),
+		domains:  make(map[string]string),
+		attempts: max(config.MaxRetries, 0) + 1,
+	}
+
+	switch config.ScanType {
+	case "", ScanTypeConnect, ScanTypeUDP:
+	case ScanTypeSYN:
+		syn, err := newSYNScanner()
+		if err != nil {
+			// 没有原始套接字权限时自动回退到全连接扫描
+			if config.Warning != nil {
+				config.Warning(fmt.Sprintf("SYN 扫描不可用，已回退到 TCP 全连接扫描: %v", err))
+			}
+			break
 		}
+		s.syn = syn
+	default:
+		return nil, fmt.Errorf("不支持的扫描方式: %s", config.ScanType)
+	}
+	return s, nil
+}
+
+func (s *portScanner) Close() {
+	if s.syn != nil {
+		s.syn.Close()
 	}
+}
 
-	wg.Wait()
-	return nil
+// scanPort 按扫描方式探测单个端口，返回端口信息及其状态，扫描被取消时 State 为空
+func (s *portScanner) scanPort(ctx context.Context, host string, port int) PortInfo {
+	switch {
+	case s.config.ScanType == ScanTypeUDP:
+		return s.udpPortState(ctx, host, port)
+	case s.syn != nil && net.ParseIP(host).To4() != nil:
+		// 原始套接字只构造 IPv4 报文，IPv6 目标退化为全连接扫描
+		return s.synPort(ctx, host, port)
+	default:
+		return s.connectPort(ctx, host, port)
+	}
+}
+
+// connectPort 通过 TCP 全连接探测端口:
+// 连接成功为 open 并在该连接上识别指纹，连接被拒绝为 closed，超时或 ICMP 不可达为 filtered
+func (s *portScanner) connectPort(ctx context.Context, host string, port int) PortInfo {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	timing := s.timings.get(host)
+	portInfo := PortInfo{Host: host, Port: port, Protocol: "tcp"}
+
+	for attempt := 0; attempt < s.attempts; attempt++ {
+		dialer := net.Dialer{Timeout: timing.Timeout()}
+		start := time.Now()
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			timing.Update(time.Since(start))
+			// 复用探活连接读取 Banner，避免对开放端口重复建连
+			return s.fingerprint(ctx, host, port, conn)
+		}
+		if ctx.Err() != nil {
+			return portInfo
+		}
+
+		if isPortUnreachable(err) {
+			// RST 同样是一次有效的往返
+			timing.Update(time.Since(start))
+			portInfo.State = StateClosed
+			return portInfo
+		}
+		if !isTimeout(err) {
+			// 主机/网络不可达等 ICMP 错误，说明探测包被拦截
+			portInfo.State = StateFiltered
+			return portInfo
+		}
+		// 超时视为可能被过滤，按当前超时重传
+	}
+	portInfo.State = StateFiltered
+	return portInfo
+}
+
+// synPort 对单个端口进行 SYN 探测: SYN/ACK 为 open，RST 为 closed，无响应为 filtered
+func (s *portScanner) synPort(ctx context.Context, host string, port int) PortInfo {
+	ip := net.ParseIP(host).To4()
+	timing := s.timings.get(host)
+	portInfo := PortInfo{Host: host, Port: port, Protocol: "tcp"}
+
+	for attempt := 0; attempt < s.attempts; attempt++ {
+		start := time.Now()
+		flags, err := s.syn.probeFlags(ctx, ip, port, tcpFlagSYN, timing.Timeout())
+		if err != nil {
+			if ctx.Err() == nil {
+				portInfo.State = StateFiltered
+			}
+			return portInfo
+		}
+		if flags == 0 {
+			// 无响应，重传
+			continue
+		}
+
+		timing.Update(time.Since(start))
+		if flags&(tcpFlagSYN|tcpFlagACK) == tcpFlagSYN|tcpFlagACK {
+			return s.fingerprint(ctx, host, port, nil)
+		}
+		portInfo.State = StateClosed
+		return portInfo
+	}
+	portInfo.State = StateFiltered
+	return portInfo
+}
+
+// fingerprint 对已确认开放的 TCP 端口进行指纹识别，TLS 端口再提取证书信息，Web 端口再请求首页，
+// 再提取服务特有的元数据并按 CPE 匹配本地 CVE 库，最后执行未授权访问检查
+func (s *portScanner) fingerprint(ctx context.Context, host string, port int, conn net.Conn) PortInfo {
+	portInfo := s.identify(host, port, conn)
+	domain := s.domains[host]
+
+	// 探针经 TLS 发送时已取得证书，不再重复握手；未识别出服务时只对探针库列出的 TLS 端口尝试握手
+	if portInfo.Certificate == nil && (isTLSService(portInfo) || (portInfo.Service == "" && isSSLPort(port))) {
+		if info, err := inspectTLS(host, port, s.serverName(host), s.config.Timeout); err == nil {
+			portInfo.TLS = true
+			portInfo.Certificate = info
+			if portInfo.Service == "" {
+				portInfo.Service = "ssl"
+			}
+		}
+	}
+
+	if isHTTPService(portInfo) {
+		if info, err := probeHTTP(host, port, portInfo.TLS, domain, s.config.Timeout); err == nil {
+			portInfo.HTTP = info
+		}
+	}
+
+	portInfo.Details = extractDetails(ctx, portInfo, s.config.Timeout)
+
+	if cpe, ok := normalizeCPE(portInfo); ok {
+		portInfo.CPE = cpe.String()
+		if s.config.CVEDB != nil {
+			portInfo.CVEs = s.config.CVEDB.Match(cpe)
+		}
+	}
+
+	if s.config.CheckUnauth {
+		portInfo.Findings = checkUnauthorized(ctx, portInfo, s.config.Timeout)
+	}
+	return portInfo
+}
+
+// serverName 返回 TLS 握手使用的 SNI，扫描域名时为域名
+func (s *portScanner) serverName(host string) string {
+	if domain := s.domains[host]; domain != "" {
+		return domain
+	}
+	return host
+}
+
+// identify 先在 conn(为空时新建一条连接)上被动读取 Banner，NULL 探针已能确定服务版本时直接返回，
+// 否则依次发送自定义探针与内置的主动探针，已读取的 Banner 不再重复探测
+func (s *portScanner) identify(host string, port int, conn net.Conn) PortInfo {
+	portInfo := PortInfo{
+		Host:     host,
+		Port:     port,
+		Protocol: "tcp",
+		State:    StateOpen,
+	}
+
+	timing := s.timings.get(host)
+	if conn == nil {
+		conn, _ = net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timing.Timeout())
+	}
+	var banner []byte
+	if conn != nil {
+		wait := timing.Timeout()
+		if wait < minBannerWait {
+			wait = minBannerWait
+		}
+		banner = grabBanner(conn, wait)
+		conn.Close()
+
+		if len(banner) > 0 {
+			portInfo.Banner = escapeBanner(banner)
+			if matchBanner(&portInfo, banner) {
+				return portInfo
+			}
+		}
+	}
+
+	deadline := time.Now().Add(s.config.Timeout)
+	serverName := s.serverName(host)
+	if probeCustomTCP(&portInfo, host, port, serverName, deadline) {
+		return portInfo
+	}
+	probeTCP(&portInfo, host, port, serverName, banner, deadline)
+	return portInfo
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
@@ -6,18 +6,57 @@ import (
 )
 
 type scanControl struct {
-	cancel     context.CancelFunc
-	totalPorts int32
-	scanned    int32
+	id       string // 扫描 ID，完成后作为历史记录 ID
+	cancel   context.CancelFunc
+	status   string       // 最近一次推送的状态，受 scanMutex 保护
+	progress ScanProgress // 最近一次上报的进度，受 scanMutex 保护
 }
 
 var (
-	currentScan *scanControl
-	scanMutex   sync.Mutex
+	scans     = make(map[string]*scanControl) // 正在进行的扫描，按扫描 ID 索引
+	scanMutex sync.Mutex
 )
 
+// ScanOptions 前端传入的扫描参数
+type ScanOptions struct {
+	Target     string `json:"target"`      // IP、CIDR、范围、逗号列表或域名
+	TargetFile string `json:"target_file"` // 可选的目标文件
+	IPPrefer   string `json:"ip_prefer"`   // 域名解析的协议族偏好: 空(双栈) / ipv4 / ipv6
+	ImportFile string `json:"import_file"` // nmap / masscan 输出文件，设置后只对其中的开放端口做指纹识别
+	Ports      string `json:"ports"`       // nmap 风格的端口表达式
+	ScanType   string `json:"scan_type"`   // connect / syn / udp
+	MaxThreads int    `json:"max_threads"`
+	// 排除的主机(IP、CIDR、范围、域名)与端口，ExcludeFile 为可选的排除主机文件
+	ExcludeHosts string `json:"exclude_hosts"`
+	ExcludeFile  string `json:"exclude_file"`
+	ExcludePorts string `json:"exclude_ports"`
+	// 跳过主机发现，将所有目标视为存活
+	SkipDiscovery bool `json:"skip_discovery"`
+	// 端口探测超时根据实测 RTT 自适应调整，以下为上下限(毫秒)与超时重传次数，0 表示使用默认值
+	MinTimeout int `json:"min_timeout"`
+	MaxTimeout int `json:"max_timeout"`
+	MaxRetries int `json:"max_retries"`
+	// 是否同时上报 closed / filtered 端口，用于防火墙策略分析
+	ReportClosed   bool `json:"report_closed"`
+	ReportFiltered bool `json:"report_filtered"`
+	// 对识别出的服务执行只读的未授权访问检查
+	CheckUnauth bool `json:"check_unauth"`
+	// 使用本地 CVE 库匹配识别出的产品版本
+	MatchCVE bool `json:"match_cve"`
+	// 端口扫描完成后对识别出的服务进行弱口令审计，仅限已获授权的评估
+	AuditCredentials  bool   `json:"audit_credentials"`
+	UserFile          string `json:"user_file"`          // 可选的用户名字典，为空时使用各服务默认用户名
+	PassFile          string `json:"pass_file"`          // 可选的口令字典，为空时使用内置弱口令
+	CredentialThreads int    `json:"credential_threads"` // 单个服务的并发数
+	CredentialDelay   int    `json:"credential_delay"`   // 同一服务两次尝试的间隔(毫秒)
+}
+
+// ScanProgress 扫描进度汇总
 type ScanProgress struct {
-	CurrentPort int32  `json:"current_port"`
-	TotalPorts  int32  `json:"total_ports"`
-	Status      string `json:"status"`
+	Scanned    int32   `json:"scanned"`
+	TotalPorts int32   `json:"total_ports"`
+	Open       int32   `json:"open"` // open 与 open|filtered 端口数
+	Rate       float64 `json:"rate"` // 每秒扫描端口数
+	ETA        float64 `json:"eta"`  // 预计剩余秒数
+	Status     string  `json:"status"`
 }
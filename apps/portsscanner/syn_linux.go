@@ -0,0 +1,256 @@
+//go:build linux
+
+package portsscanner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math/rand"
+	"net"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// TCP 标志位
+const (
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagACK = 0x10
+)
+
+type synKey struct {
+	ip   [4]byte
+	port uint16
+}
+
+// synScanner 基于原始套接字的半连接扫描器。
+// 发送 SYN 后根据 SYN/ACK 或 RST 判断端口状态，内核会对未知连接的 SYN/ACK 自动回复 RST
+type synScanner struct {
+	fd      int
+	srcPort uint16
+	secret  uint32
+
+	mu      sync.Mutex
+	waiters map[synKey]chan byte
+	srcIPs  sync.Map // 目标IP -> 本地出口IP
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+func newSYNScanner() (*synScanner, error) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_RAW, syscall.IPPROTO_TCP)
+	if err != nil {
+		if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES) {
+			return nil, fmt.Errorf("SYN 扫描需要 root 或 CAP_NET_RAW 权限: %w", err)
+		}
+		return nil, fmt.Errorf("创建原始套接字失败: %w", err)
+	}
+
+	// 接收超时用于定期检查扫描器是否已关闭
+	tv := syscall.NsecToTimeval(int64(200 * time.Millisecond))
+	if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+		syscall.Close(fd)
+		return nil, fmt.Errorf("设置原始套接字超时失败: %w", err)
+	}
+
+	s := &synScanner{
+		fd:      fd,
+		srcPort: uint16(32768 + rand.Intn(28000)),
+		secret:  rand.Uint32(),
+		waiters: make(map[synKey]chan byte),
+		done:    make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.receiveLoop()
+	return s, nil
+}
+
+// Close 停止接收并等待接收循环退出后再关闭原始套接字，
+// 接收超时保证循环在 Recvfrom 中阻塞不超过 200ms
+func (s *synScanner) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+		syscall.Close(s.fd)
+	})
+}
+
+// probeFlags 发送指定标志位的 TCP 探测包并等待响应，返回响应包的 TCP 标志位，超时无响应时返回 0。
+// ACK 探测用于穿透只拦截 SYN 的防火墙
+func (s *synScanner) probeFlags(ctx context.Context, ip net.IP, port int, flags byte, timeout time.Duration) (byte, error) {
+	var key synKey
+	copy(key.ip[:], ip)
+	key.port = uint16(port)
+
+	ch := make(chan byte, 1)
+	s.mu.Lock()
+	s.waiters[key] = ch
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.waiters, key)
+		s.mu.Unlock()
+	}()
+
+	if err := s.send(ip, uint16(port), flags); err != nil {
+		return 0, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-ch:
+		return reply, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case <-s.done:
+		return 0, net.ErrClosed
+	case <-timer.C:
+		return 0, nil
+	}
+}
+
+func (s *synScanner) send(dst net.IP, dstPort uint16, flags byte) error {
+	src, err := s.sourceIP(dst)
+	if err != nil {
+		return err
+	}
+
+	// 20 字节 TCP 头 + 选项。SYN 与常见系统一样携带 MSS、SACK、时间戳与窗口扩大选项，
+	// 对端 SYN/ACK 中的选项顺序才能用于推测操作系统；ACK 探测只带 MSS
+	options := []byte{0x02, 0x04, 0x05, 0xb4} // MSS 1460
+	if flags&tcpFlagSYN != 0 {
+		options = []byte{
+			0x02, 0x04, 0x05, 0xb4, // MSS 1460
+			0x04, 0x02, // SACK permitted
+			0x08, 0x0a, 0, 0, 0, 0, 0, 0, 0, 0, // 时间戳
+			0x01,             // NOP
+			0x03, 0x03, 0x07, // 窗口扩大 7
+		}
+		binary.BigEndian.PutUint32(options[8:12], uint32(time.Now().UnixMilli()))
+	}
+	pkt := make([]byte, 20+len(options))
+	binary.BigEndian.PutUint16(pkt[0:2], s.srcPort)
+	binary.BigEndian.PutUint16(pkt[2:4], dstPort)
+	binary.BigEndian.PutUint32(pkt[4:8], s.sequence(dst, dstPort))
+	if flags&tcpFlagACK != 0 {
+		binary.BigEndian.PutUint32(pkt[8:12], s.secret)
+	}
+	pkt[12] = byte(len(pkt)/4) << 4 // 数据偏移，单位为 32 位字
+	pkt[13] = flags
+	binary.BigEndian.PutUint16(pkt[14:16], 1024)
+	copy(pkt[20:], options)
+	binary.BigEndian.PutUint16(pkt[16:18], tcpChecksum(src, dst, pkt))
+
+	addr := &syscall.SockaddrInet4{}
+	copy(addr.Addr[:], dst)
+	return syscall.Sendto(s.fd, pkt, 0, addr)
+}
+
+// sequence 根据目标计算初始序列号，用于校验响应是否属于本次扫描
+func (s *synScanner) sequence(dst net.IP, port uint16) uint32 {
+	buf := make([]byte, 6)
+	copy(buf, dst.To4())
+	binary.BigEndian.PutUint16(buf[4:], port)
+	return crc32.ChecksumIEEE(buf) ^ s.secret
+}
+
+// sourceIP 通过路由查找确定发往目标时使用的本地地址
+func (s *synScanner) sourceIP(dst net.IP) (net.IP, error) {
+	if v, ok := s.srcIPs.Load(dst.String()); ok {
+		return v.(net.IP), nil
+	}
+
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: dst, Port: 9})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	src := conn.LocalAddr().(*net.UDPAddr).IP.To4()
+	s.srcIPs.Store(dst.String(), src)
+	return src, nil
+}
+
+func (s *synScanner) receiveLoop() {
+	defer s.wg.Done()
+	buf := make([]byte, 65535)
+	for {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+
+		n, _, err := syscall.Recvfrom(s.fd, buf, 0)
+		if err != nil {
+			continue
+		}
+		s.handlePacket(buf[:n])
+	}
+}
+
+// handlePacket 解析收到的 IPv4 + TCP 报文，将属于本扫描器的响应分发给等待方
+func (s *synScanner) handlePacket(pkt []byte) {
+	if len(pkt) < 20 || pkt[0]>>4 != 4 || pkt[9] != syscall.IPPROTO_TCP {
+		return
+	}
+	ihl := int(pkt[0]&0x0f) * 4
+	if len(pkt) < ihl+20 {
+		return
+	}
+	tcp := pkt[ihl:]
+
+	if binary.BigEndian.Uint16(tcp[2:4]) != s.srcPort {
+		return
+	}
+
+	var key synKey
+	copy(key.ip[:], pkt[12:16])
+	key.port = binary.BigEndian.Uint16(tcp[0:2])
+
+	flags := tcp[13]
+	ack := binary.BigEndian.Uint32(tcp[8:12])
+	if flags&tcpFlagACK != 0 && ack != s.sequence(net.IP(key.ip[:]), key.port)+1 {
+		return
+	}
+
+	s.mu.Lock()
+	ch, ok := s.waiters[key]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case ch <- flags:
+	default:
+	}
+}
+
+func tcpChecksum(src, dst net.IP, segment []byte) uint16 {
+	pseudo := make([]byte, 12, 12+len(segment))
+	copy(pseudo[0:4], src.To4())
+	copy(pseudo[4:8], dst.To4())
+	pseudo[9] = syscall.IPPROTO_TCP
+	binary.BigEndian.PutUint16(pseudo[10:12], uint16(len(segment)))
+	data := append(pseudo, segment...)
+
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(data[i : i+2]))
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+
+package portsscanner
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	tcpFlagSYN = 0x02
+	tcpFlagRST = 0x04
+	tcpFlagACK = 0x10
+)
+
+// synScanner 非 Linux 平台不支持原始套接字半连接扫描
+type synScanner struct{}
+
+func newSYNScanner() (*synScanner, error) {
+	return nil, fmt.Errorf("SYN 扫描仅支持 Linux")
+}
+
+func (s *synScanner) Close() {}
+
+func (s *synScanner) probeFlags(ctx context.Context, ip net.IP, port int, flags byte, timeout time.Duration) (byte, error) {
+	return 0, fmt.Errorf("SYN 扫描仅支持 Linux")
+}
@@ -0,0 +1,247 @@
+package portsscanner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 单次扫描允许展开的最大主机数，防止误输入 /8 之类的网段
+const maxTargets = 1 << 16
+
+// 域名解析到多个协议族的地址时的取舍
+const (
+	IPPreferDual = ""     // 扫描全部 A/AAAA 记录
+	IPPreferV4   = "ipv4" // 有 A 记录时只扫描 IPv4 地址
+	IPPreferV6   = "ipv6" // 有 AAAA 记录时只扫描 IPv6 地址
+)
+
+// Target 扫描目标，IP 为实际连接的地址，Domain 为解析前的域名(若有)
+type Target struct {
+	IP     string        `json:"ip"`
+	Domain string        `json:"domain,omitempty"`
+	RTT    time.Duration `json:"-"` // 主机发现阶段测得的往返时延，用于初始化端口探测超时
+	// 超过 /112 的 IPv6 链路网段，此时 IP 为空，由主机发现阶段展开为链路上的主机
+	Network string `json:"network,omitempty"`
+}
+
+// Label 返回用于展示的目标，链路网段返回网段本身
+func (t Target) Label() string {
+	if t.Network != "" {
+		return t.Network
+	}
+	return t.IP
+}
+
+// ParseTargets 解析目标表达式并展开为主机列表
+// 支持: 单个IP、CIDR(192.168.1.0/24、2001:db8::/120)、范围(10.0.0.1-50 / 10.0.0.1-10.0.0.50 / 2001:db8::10-1f)、
+// 逗号/空白分隔的列表，以及域名(prefer 决定使用 A、AAAA 还是全部记录)。
+// 超过 /112 的 IPv6 网段无法逐个展开，仅支持本机直连的链路，解析时只保留网段，
+// 由 DiscoverHosts 通过 ICMPv6 组播与邻居表发现其中的主机
+func ParseTargets(ctx context.Context, expr string, prefer string) ([]Target, error) {
+	var targets []Target
+	seen := make(map[string]bool)
+
+	for _, item := range splitTargetExpr(expr) {
+		expanded, err := expandTarget(ctx, item, prefer)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range expanded {
+			if seen[t.Label()] {
+				continue
+			}
+			seen[t.Label()] = true
+			targets = append(targets, t)
+			if len(targets) > maxTargets {
+				return nil, fmt.Errorf("目标数量超过上限 %d", maxTargets)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// LoadTargetFile 读取目标文件，每行一个目标表达式，# 开头为注释
+func LoadTargetFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("读取目标文件失败: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("读取目标文件失败: %w", err)
+	}
+
+	return strings.Join(lines, ","), nil
+}
+
+func splitTargetExpr(expr string) []string {
+	return strings.FieldsFunc(expr, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+}
+
+func expandTarget(ctx context.Context, item string, prefer string) ([]Target, error) {
+	if strings.Contains(item, "/") {
+		return expandCIDR(item)
+	}
+
+	if ip := net.ParseIP(item); ip != nil {
+		return []Target{{IP: ip.String()}}, nil
+	}
+
+	if idx := strings.LastIndex(item, "-"); idx > 0 {
+		if start := net.ParseIP(item[:idx]); start != nil {
+			return expandRange(start, item[idx+1:])
+		}
+	}
+
+	return resolveHost(ctx, item, prefer)
+}
+
+func expandCIDR(item string) ([]Target, error) {
+	ip, ipNet, err := net.ParseCIDR(item)
+	if err != nil {
+		return nil, fmt.Errorf("无效的网段: %s", item)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if bits-ones > 16 {
+		if ip.To4() == nil {
+			// IPv6 网段无法逐个探测，只能在直连链路上发现主机
+			if _, src := linkInterface(ipNet); src == nil {
+				return nil, fmt.Errorf("IPv6 网段 %s 过大，仅支持本机直连链路上的网段或 /112 以内的网段", item)
+			}
+			return []Target{{Network: ipNet.String()}}, nil
+		}
+		return nil, fmt.Errorf("网段 %s 过大，最多支持 %d 个地址", item, maxTargets)
+	}
+
+	var targets []Target
+	for cur := ip.Mask(ipNet.Mask); ipNet.Contains(cur); cur = nextIP(cur) {
+		targets = append(targets, Target{IP: cur.String()})
+	}
+
+	// IPv4 网段去掉网络地址和广播地址
+	if ip.To4() != nil && len(targets) > 2 {
+		targets = targets[1 : len(targets)-1]
+	}
+	return targets, nil
+}
+
+func expandRange(start net.IP, endExpr string) ([]Target, error) {
+	end := parseRangeEnd(start, endExpr)
+	if end == nil {
+		return nil, fmt.Errorf("无效的地址范围: %s-%s", start, endExpr)
+	}
+
+	if (start.To4() == nil) != (end.To4() == nil) {
+		return nil, fmt.Errorf("地址范围两端协议族不一致: %s-%s", start, end)
+	}
+	if compareIP(start, end) > 0 {
+		return nil, fmt.Errorf("起始地址不能大于结束地址: %s-%s", start, end)
+	}
+
+	var targets []Target
+	for cur := start; compareIP(cur, end) <= 0; cur = nextIP(cur) {
+		targets = append(targets, Target{IP: cur.String()})
+		if len(targets) > maxTargets {
+			return nil, fmt.Errorf("地址范围 %s-%s 过大，最多支持 %d 个地址", start, end, maxTargets)
+		}
+	}
+	return targets, nil
+}
+
+// parseRangeEnd 解析范围的结束地址，省略形式只替换最后一段:
+// IPv4 为十进制(10.0.0.1-50)，IPv6 为十六进制(2001:db8::10-1f)，无效时返回 nil
+func parseRangeEnd(start net.IP, endExpr string) net.IP {
+	if strings.ContainsAny(endExpr, ".:") {
+		return net.ParseIP(endExpr)
+	}
+	if v4 := start.To4(); v4 != nil {
+		n, err := strconv.ParseUint(endExpr, 10, 8)
+		if err != nil {
+			return nil
+		}
+		return net.IPv4(v4[0], v4[1], v4[2], byte(n))
+	}
+	n, err := strconv.ParseUint(endExpr, 16, 16)
+	if err != nil {
+		return nil
+	}
+	end := append(net.IP(nil), start.To16()...)
+	end[14], end[15] = byte(n>>8), byte(n)
+	return end
+}
+
+// resolveHost 解析域名，按 prefer 在 A 与 AAAA 记录间取舍，偏好的协议族没有记录时使用另一协议族
+func resolveHost(ctx context.Context, host string, prefer string) ([]Target, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("解析域名 %s 失败: %w", host, err)
+	}
+
+	var v4, v6 []Target
+	for _, addr := range addrs {
+		t := Target{IP: addr.IP.String(), Domain: host}
+		if addr.IP.To4() != nil {
+			v4 = append(v4, t)
+		} else {
+			v6 = append(v6, t)
+		}
+	}
+
+	switch {
+	case prefer == IPPreferV4 && len(v4) > 0:
+		return v4, nil
+	case prefer == IPPreferV6 && len(v6) > 0:
+		return v6, nil
+	}
+	return append(v4, v6...), nil
+}
+
+func normalizeIP(ip net.IP) net.IP {
+	if v4 := ip.To4(); v4 != nil {
+		return v4
+	}
+	return ip.To16()
+}
+
+func nextIP(ip net.IP) net.IP {
+	next := append(net.IP(nil), normalizeIP(ip)...)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+func compareIP(a, b net.IP) int {
+	a, b = normalizeIP(a), normalizeIP(b)
+	if len(a) != len(b) {
+		return len(a) - len(b)
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return int(a[i]) - int(b[i])
+		}
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+package portsscanner
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+)
+
+func targetIPs(targets []Target) []string {
+	ips := make([]string, 0, len(targets))
+	for _, t := range targets {
+		ips = append(ips, t.IP)
+	}
+	return ips
+}
+
+func TestParseTargets(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"192.168.1.10", []string{"192.168.1.10"}},
+		{"10.0.0.1, 10.0.0.2;10.0.0.3\n10.0.0.1", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"192.168.1.0/30", []string{"192.168.1.1", "192.168.1.2"}},
+		{"192.168.1.5/32", []string{"192.168.1.5"}},
+		{"192.168.1.4/31", []string{"192.168.1.4", "192.168.1.5"}},
+		{"10.0.0.1-3", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"10.0.0.254-10.0.1.1", []string{"10.0.0.254", "10.0.0.255", "10.0.1.0", "10.0.1.1"}},
+		{"2001:db8::1", []string{"2001:db8::1"}},
+		{"2001:DB8::1", []string{"2001:db8::1"}},
+		{"2001:db8::/126", []string{"2001:db8::", "2001:db8::1", "2001:db8::2", "2001:db8::3"}},
+		{"2001:db8::e-11", []string{"2001:db8::e", "2001:db8::f", "2001:db8::10", "2001:db8::11"}},
+		{"2001:db8::ff-2001:db8::101", []string{"2001:db8::ff", "2001:db8::100", "2001:db8::101"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseTargets(context.Background(), tt.expr, IPPreferDual)
+		if err != nil {
+			t.Errorf("ParseTargets(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if ips := targetIPs(got); !slices.Equal(ips, tt.want) {
+			t.Errorf("ParseTargets(%q) = %v, want %v", tt.expr, ips, tt.want)
+		}
+	}
+}
+
+func TestParseTargetsCounts(t *testing.T) {
+	tests := []struct {
+		expr  string
+		count int
+	}{
+		{"10.0.0.0/24", 254},
+		{"10.0.0.0/16", 65534},
+		{"2001:db8::/112", 65536},
+		{"10.0.0.0/24,10.0.0.100-200", 254},
+	}
+	for _, tt := range tests {
+		got, err := ParseTargets(context.Background(), tt.expr, IPPreferDual)
+		if err != nil {
+			t.Errorf("ParseTargets(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if len(got) != tt.count {
+			t.Errorf("ParseTargets(%q) returned %d targets, want %d", tt.expr, len(got), tt.count)
+		}
+	}
+}
+
+func TestParseTargetsErrors(t *testing.T) {
+	for _, expr := range []string{
+		"10.0.0.0/15",
+		"10.0.0.0/33",
+		"10.0.0.1/abc",
+		"10.0.0.0/16,10.1.0.0/24",
+		"2001:db8::/64",
+	} {
+		if got, err := ParseTargets(context.Background(), expr, IPPreferDual); err == nil {
+			t.Errorf("ParseTargets(%q) = %d targets, want error", expr, len(got))
+		}
+	}
+}
+
+func TestExpandRange(t *testing.T) {
+	tests := []struct {
+		start   string
+		end     string
+		want    []string
+		wantErr bool
+	}{
+		{"10.0.0.1", "1", []string{"10.0.0.1"}, false},
+		{"10.0.0.250", "255", []string{"10.0.0.250", "10.0.0.251", "10.0.0.252", "10.0.0.253", "10.0.0.254", "10.0.0.255"}, false},
+		{"10.0.0.1", "10.0.0.2", []string{"10.0.0.1", "10.0.0.2"}, false},
+		{"2001:db8::fffe", "2001:db8::1:0", []string{"2001:db8::fffe", "2001:db8::ffff", "2001:db8::1:0"}, false},
+		{"10.0.0.5", "1", nil, true},
+		{"10.0.0.1", "256", nil, true},
+		{"10.0.0.1", "abc", nil, true},
+		{"10.0.0.1", "2001:db8::1", nil, true},
+		{"2001:db8::1", "10000", nil, true},
+		{"10.0.0.0", "10.1.0.1", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := expandRange(net.ParseIP(tt.start), tt.end)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("expandRange(%s, %s) = %d targets, want error", tt.start, tt.end, len(got))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandRange(%s, %s) error: %v", tt.start, tt.end, err)
+			continue
+		}
+		if ips := targetIPs(got); !slices.Equal(ips, tt.want) {
+			t.Errorf("expandRange(%s, %s) = %v, want %v", tt.start, tt.end, ips, tt.want)
+		}
+	}
+}
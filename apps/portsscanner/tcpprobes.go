@@ -0,0 +1,211 @@
+package portsscanner
+
+import (
+	"bytes"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/lcvvvv/gonmap"
+)
+
+// 主动探测的探针顺序与 gonmap 一致: 通用探针、端口专用探针、TLS 探针
+var (
+	commonTCPProbes = []string{"TCP_GetRequest"}
+	sslTCPProbes    = []string{"TCP_TLSSessionReq", "TCP_SSLSessionReq", "TCP_SSLv23SessionReq"}
+	// 识别为 ssl 后尝试的探针，均未识别内层服务时再通过 TLS 发送 GetRequest
+	sslSecondTCPProbes = []string{"TCP_TerminalServerCookie", "TCP_TerminalServer"}
+	// 这些端口的专用探针先于通用探针发送
+	portFirstTCPPorts = []int{161, 137, 139, 135, 389, 443, 548, 1433, 6379, 1883, 5432, 1521, 3389, 3388, 33890, 33900}
+
+	httpStatusLineRe = regexp.MustCompile(`^HTTP/1\.[01] \d{3} `)
+)
+
+const (
+	// 端口专用探针的 rarity 上限
+	maxTCPProbeRarity = 9
+	// 单个探针等待响应的最长时间
+	maxTCPProbeWait = 2 * time.Second
+)
+
+// probeTCP 在 deadline 前依次发送内置的主动探针，命中指纹时填充 portInfo 并返回 true。
+// banner 为被动读取到的 NULL 探针响应，不再重复获取，主动探针均未命中时用它的 softmatch 结果兜底。
+// 探针经 TLS 发送时顺带记录证书，serverName 用于 SNI
+func probeTCP(portInfo *PortInfo, host string, port int, serverName string, banner []byte, deadline time.Time) bool {
+	if port == 53 && gonmap.DnsScan(host, port) {
+		portInfo.Service = "dns"
+		return true
+	}
+
+	db := tcpProbeDB()
+	if fp := sendTCPProbes(portInfo, db, tcpProbesForPort(db, port), host, port, serverName, deadline); fp != nil {
+		if fp.Service == "ssl" {
+			if inner := probeInsideTLS(portInfo, db, host, port, serverName, deadline); inner != nil {
+				fp = inner
+			}
+		}
+		fillFingerPrint(portInfo, fp)
+		return true
+	}
+
+	if null, ok := db.byName["TCP_NULL"]; ok && len(banner) > 0 {
+		if fp := db.match(null, banner); fp.Service != "" {
+			fillFingerPrint(portInfo, fp)
+			return true
+		}
+	}
+	return false
+}
+
+// tcpProbesForPort 返回主动探测的探针，NULL 探针已在被动读取 Banner 时完成，不包含在内
+func tcpProbesForPort(db *probeDB, port int) []*serviceProbe {
+	// 自定义的独立探针已由 probeCustomTCP 发送，这里只选取内置探针
+	var specific []string
+	for _, p := range builtinProbeDB("TCP").probesForPort(port) {
+		if p.rarity <= maxTCPProbeRarity {
+			specific = append(specific, p.name)
+		}
+	}
+
+	var names []string
+	if containsPort(portFirstTCPPorts, port) {
+		names = append(specific, commonTCPProbes...)
+	} else {
+		names = append(append(names, commonTCPProbes...), specific...)
+	}
+	names = append(names, sslTCPProbes...)
+	return lookupTCPProbes(db, names)
+}
+
+// lookupTCPProbes 按名称取出探针，跳过重复、不存在以及没有载荷的探针
+func lookupTCPProbes(db *probeDB, names []string) []*serviceProbe {
+	seen := make(map[string]bool)
+	var result []*serviceProbe
+	for _, name := range names {
+		p, ok := db.byName[name]
+		if !ok || seen[name] || len(p.payload) == 0 {
+			continue
+		}
+		seen[name] = true
+		result = append(result, p)
+	}
+	return result
+}
+
+// sendTCPProbes 依次发送探针，返回第一个识别出服务的指纹，均未命中时返回 nil
+func sendTCPProbes(portInfo *PortInfo, db *probeDB, probes []*serviceProbe, host string, port int, serverName string, deadline time.Time) *gonmap.FingerPrint {
+	for _, p := range probes {
+		if !time.Now().Before(deadline) {
+			break
+		}
+		response, tlsInfo, err := sendTCPProbe(host, port, serverName, p.payload, containsPort(p.sslports, port), deadline)
+		if tlsInfo != nil {
+			portInfo.TLS = true
+			portInfo.Certificate = tlsInfo
+		}
+		if err != nil || len(response) == 0 {
+			continue
+		}
+		if portInfo.Banner == "" {
+			portInfo.Banner = escapeBanner(response)
+		}
+
+		if fp := matchTCPResponse(db, p, response); fp.Service != "" {
+			return fp
+		}
+	}
+	return nil
+}
+
+// probeInsideTLS 在识别为 ssl 的端口上继续识别 TLS 承载的服务，与 gonmap 一致将 http 记为 https
+func probeInsideTLS(portInfo *PortInfo, db *probeDB, host string, port int, serverName string, deadline time.Time) *gonmap.FingerPrint {
+	if fp := sendTCPProbes(portInfo, db, lookupTCPProbes(db, sslSecondTCPProbes), host, port, serverName, deadline); fp != nil && fp.Service != "ssl" {
+		return fp
+	}
+
+	p, ok := db.byName["TCP_GetRequest"]
+	if !ok || !time.Now().Before(deadline) {
+		return nil
+	}
+	response, tlsInfo, err := sendTCPProbe(host, port, serverName, p.payload, true, deadline)
+	if tlsInfo == nil {
+		return nil
+	}
+	portInfo.TLS = true
+	portInfo.Certificate = tlsInfo
+	if err != nil || len(response) == 0 {
+		return nil
+	}
+	fp := matchTCPResponse(db, p, response)
+	if fp.Service == "" || fp.Service == "ssl" {
+		return nil
+	}
+	if fp.Service == "http" {
+		fp.Service = "https"
+	}
+	return fp
+}
+
+// matchTCPResponse 使用探针及其 fallback 匹配响应，规则库未收录的 Web 服务按状态行识别为 http，
+// 服务端软件等信息由之后的 probeHTTP 补充
+func matchTCPResponse(db *probeDB, p *serviceProbe, response []byte) *gonmap.FingerPrint {
+	fp := db.match(p, response)
+	if fp.Service == "" && httpStatusLineRe.Match(response) {
+		fp.Service = "http"
+	}
+	return fp
+}
+
+// isSSLPort 判断探针库是否将端口列为 TLS 端口
+func isSSLPort(port int) bool {
+	for _, p := range builtinProbeDB("TCP").probes {
+		if containsPort(p.sslports, port) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendTCPProbe 新建连接发送载荷并读取响应，useTLS 时先尝试 TLS 握手，失败则退回明文。
+// 握手成功时返回证书信息，探针连接不协商 ALPN，避免服务端选择 h2 后无法识别明文请求
+func sendTCPProbe(host string, port int, serverName string, payload []byte, useTLS bool, deadline time.Time) ([]byte, *TLSInfo, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Deadline: deadline}
+
+	var (
+		conn    net.Conn
+		tlsInfo *TLSInfo
+	)
+	if useTLS {
+		if rawConn, err := dialer.Dial("tcp", addr); err == nil {
+			_ = rawConn.SetDeadline(deadline)
+			if tlsConn, info, err := handshakeTLS(rawConn, serverName, nil); err == nil {
+				conn, tlsInfo = tlsConn, info
+			} else {
+				rawConn.Close()
+			}
+		}
+	}
+	if conn == nil {
+		plain, err := dialer.Dial("tcp", addr)
+		if err != nil {
+			return nil, nil, err
+		}
+		conn = plain
+	}
+	defer conn.Close()
+
+	// GetRequest 等探针的 Host 头使用占位符
+	payload = bytes.ReplaceAll(payload, []byte("{Host}"), []byte(addr))
+	_ = conn.SetWriteDeadline(deadline)
+	if _, err := conn.Write(payload); err != nil {
+		return nil, tlsInfo, err
+	}
+
+	wait := time.Until(deadline)
+	if wait > maxTCPProbeWait {
+		wait = maxTCPProbeWait
+	}
+	return grabBanner(conn, wait), tlsInfo, nil
+}
@@ -0,0 +1,219 @@
+package portsscanner
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/md5"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLSInfo TLS 握手结果与服务端证书信息
+type TLSInfo struct {
+	Subject      string    `json:"subject"`
+	CommonName   string    `json:"common_name"`
+	SANs         []string  `json:"sans,omitempty"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	KeyType      string    `json:"key_type"`
+	KeySize      int       `json:"key_size"`
+	Version      string    `json:"version"`      // 协商的协议版本
+	CipherSuite  string    `json:"cipher_suite"` // 协商的加密套件
+	ALPN         string    `json:"alpn,omitempty"`
+	JA3S         string    `json:"ja3s"`      // ServerHello 指纹原文: 版本,套件,扩展列表
+	JA3SHash     string    `json:"ja3s_hash"` // JA3S 的 MD5
+
+	Expired          bool `json:"expired"`
+	SelfSigned       bool `json:"self_signed"`
+	HostnameMismatch bool `json:"hostname_mismatch"`
+}
+
+// tlsServices 以 TLS 承载、需要提取证书的服务名
+var tlsServices = map[string]bool{
+	"https": true, "ssl": true, "imaps": true, "pop3s": true, "smtps": true,
+	"ldaps": true, "ftps": true, "ircs": true, "xmpps": true,
+}
+
+// allCipherSuites 包含 Go 实现的全部加密套件，默认列表不含 RC4、3DES 等不安全套件，
+// 只支持这些套件的旧服务会握手失败
+var allCipherSuites = func() []uint16 {
+	var ids []uint16
+	for _, suites := range [][]*tls.CipherSuite{tls.CipherSuites(), tls.InsecureCipherSuites()} {
+		for _, suite := range suites {
+			ids = append(ids, suite.ID)
+		}
+	}
+	return ids
+}()
+
+// isTLSService 判断端口是否承载 TLS
+func isTLSService(portInfo PortInfo) bool {
+	service := strings.ToLower(portInfo.Service)
+	return portInfo.TLS || tlsServices[service] || strings.HasPrefix(service, "ssl/")
+}
+
+// inspectTLS 与目标完成一次 TLS 握手并提取证书信息，serverName 用于 SNI 与主机名校验
+func inspectTLS(host string, port int, serverName string, timeout time.Duration) (*TLSInfo, error) {
+	rawConn, err := net.DialTimeout("tcp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer rawConn.Close()
+	_ = rawConn.SetDeadline(time.Now().Add(timeout))
+
+	_, info, err := handshakeTLS(rawConn, serverName, []string{"h2", "http/1.1"})
+	return info, err
+}
+
+// handshakeTLS 在已建立的连接上完成 TLS 握手并提取证书信息，返回的连接可继续收发数据。
+// alpn 为客户端提供的应用层协议，发送探针的连接不应提供 h2
+func handshakeTLS(rawConn net.Conn, serverName string, alpn []string) (*tls.Conn, *TLSInfo, error) {
+	// 记录握手阶段收到的原始数据用于计算 JA3S
+	recorder := &recordingConn{Conn: rawConn}
+	config := &tls.Config{
+		InsecureSkipVerify: true,
+		MinVersion:         tls.VersionTLS10,
+		CipherSuites:       allCipherSuites,
+		NextProtos:         alpn,
+	}
+	if net.ParseIP(serverName) == nil {
+		config.ServerName = serverName
+	}
+
+	conn := tls.Client(recorder, config)
+	if err := conn.Handshake(); err != nil {
+		return nil, nil, err
+	}
+	state := conn.ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return nil, nil, fmt.Errorf("服务端未提供证书")
+	}
+
+	cert := state.PeerCertificates[0]
+	info := &TLSInfo{
+		Subject:      cert.Subject.String(),
+		CommonName:   cert.Subject.CommonName,
+		Issuer:       cert.Issuer.String(),
+		SerialNumber: strings.ToUpper(cert.SerialNumber.Text(16)),
+		NotBefore:    cert.NotBefore,
+		NotAfter:     cert.NotAfter,
+		Version:      tls.VersionName(state.Version),
+		CipherSuite:  tls.CipherSuiteName(state.CipherSuite),
+		ALPN:         state.NegotiatedProtocol,
+	}
+	info.SANs = append(info.SANs, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	info.KeyType, info.KeySize = publicKeyInfo(cert)
+
+	now := time.Now()
+	info.Expired = now.After(cert.NotAfter) || now.Before(cert.NotBefore)
+	info.SelfSigned = bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+	// 只有扫描的是域名时才校验主机名，多数证书不包含 IP SAN
+	if net.ParseIP(serverName) == nil {
+		info.HostnameMismatch = cert.VerifyHostname(serverName) != nil
+	}
+
+	if ja3s, ok := parseJA3S(recorder.buf.Bytes()); ok {
+		sum := md5.Sum([]byte(ja3s))
+		info.JA3S = ja3s
+		info.JA3SHash = hex.EncodeToString(sum[:])
+	}
+	return conn, info, nil
+}
+
+func publicKeyInfo(cert *x509.Certificate) (string, int) {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return "RSA", key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return "ECDSA", key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return "Ed25519", 256
+	}
+	return cert.PublicKeyAlgorithm.String(), 0
+}
+
+// 握手阶段最多记录的数据量，ServerHello 总在最前面
+const maxRecordedHandshake = 16 * 1024
+
+// recordingConn 记录读取到的数据
+type recordingConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *recordingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 && c.buf.Len() < maxRecordedHandshake {
+		c.buf.Write(p[:n])
+	}
+	return n, err
+}
+
+// parseJA3S 从服务端发送的 TLS 记录中解析 ServerHello，
+// 生成 "版本,加密套件,扩展1-扩展2-..." 形式的 JA3S 原文
+func parseJA3S(data []byte) (string, bool) {
+	// 拼接连续握手记录的负载，ServerHello 可能被拆分到多个记录
+	var handshake []byte
+	for len(data) >= 5 && data[0] == 0x16 {
+		length := int(binary.BigEndian.Uint16(data[3:5]))
+		if len(data) < 5+length {
+			handshake = append(handshake, data[5:]...)
+			break
+		}
+		handshake = append(handshake, data[5:5+length]...)
+		data = data[5+length:]
+	}
+
+	if len(handshake) < 4 || handshake[0] != 0x02 {
+		return "", false
+	}
+	// 记录被截断时扩展列表不完整，不生成 JA3S
+	body := handshake[4:]
+	length := int(handshake[1])<<16 | int(handshake[2])<<8 | int(handshake[3])
+	if len(body) < length {
+		return "", false
+	}
+	body = body[:length]
+
+	// server_version(2) + random(32) + session_id
+	if len(body) < 35 {
+		return "", false
+	}
+	version := binary.BigEndian.Uint16(body[0:2])
+	offset := 34
+	offset += 1 + int(body[34])
+	// cipher_suite(2) + compression_method(1)
+	if len(body) < offset+3 {
+		return "", false
+	}
+	cipher := binary.BigEndian.Uint16(body[offset : offset+2])
+	offset += 3
+
+	var extensions []string
+	if len(body) >= offset+2 {
+		extEnd := offset + 2 + int(binary.BigEndian.Uint16(body[offset:offset+2]))
+		offset += 2
+		for offset+4 <= len(body) && offset+4 <= extEnd {
+			extType := binary.BigEndian.Uint16(body[offset : offset+2])
+			extLen := int(binary.BigEndian.Uint16(body[offset+2 : offset+4]))
+			extensions = append(extensions, strconv.Itoa(int(extType)))
+			offset += 4 + extLen
+		}
+	}
+
+	return fmt.Sprintf("%d,%d,%s", version, cipher, strings.Join(extensions, "-")), true
+}
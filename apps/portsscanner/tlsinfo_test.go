@@ -0,0 +1,80 @@
+package portsscanner
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// serverHello 构造 ServerHello 握手消息，exts 为 扩展类型 -> 扩展数据
+func serverHello(version, cipher uint16, sessionID []byte, exts [][2]any) []byte {
+	body := binary.BigEndian.AppendUint16(nil, version)
+	body = append(body, make([]byte, 32)...)
+	body = append(body, byte(len(sessionID)))
+	body = append(body, sessionID...)
+	body = binary.BigEndian.AppendUint16(body, cipher)
+	body = append(body, 0)
+
+	if exts != nil {
+		var list []byte
+		for _, ext := range exts {
+			data := ext[1].([]byte)
+			list = binary.BigEndian.AppendUint16(list, ext[0].(uint16))
+			list = binary.BigEndian.AppendUint16(list, uint16(len(data)))
+			list = append(list, data...)
+		}
+		body = binary.BigEndian.AppendUint16(body, uint16(len(list)))
+		body = append(body, list...)
+	}
+
+	msg := []byte{0x02, byte(len(body) >> 16), byte(len(body) >> 8), byte(len(body))}
+	return append(msg, body...)
+}
+
+// tlsRecords 将握手消息按 size 拆分为多个握手记录
+func tlsRecords(msg []byte, size int) []byte {
+	var out []byte
+	for len(msg) > 0 {
+		n := min(size, len(msg))
+		out = append(out, 0x16, 0x03, 0x03, byte(n>>8), byte(n))
+		out = append(out, msg[:n]...)
+		msg = msg[n:]
+	}
+	return out
+}
+
+func TestParseJA3S(t *testing.T) {
+	tls12 := serverHello(0x0303, 0xc02f, []byte{1, 2, 3, 4}, [][2]any{
+		{uint16(0xff01), []byte{0}},
+		{uint16(0x000b), []byte{1, 0}},
+		{uint16(0x0023), []byte{}},
+		{uint16(0x0010), []byte{0, 3, 2, 'h', '2'}},
+	})
+	tls13 := serverHello(0x0303, 0x1301, make([]byte, 32), [][2]any{
+		{uint16(43), []byte{0x03, 0x04}},
+		{uint16(51), make([]byte, 36)},
+	})
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+		ok   bool
+	}{
+		{"tls 1.2", tlsRecords(tls12, 1<<14), "771,49199,65281-11-35-16", true},
+		{"tls 1.3", tlsRecords(tls13, 1<<14), "771,4865,43-51", true},
+		{"tls 1.0 without extensions", tlsRecords(serverHello(0x0301, 0x002f, nil, nil), 1<<14), "769,47,", true},
+		{"split across records", tlsRecords(tls12, 7), "771,49199,65281-11-35-16", true},
+		{"followed by certificate", append(tlsRecords(tls12, 1<<14), 0x16, 0x03, 0x03, 0x00, 0x04, 0x0b, 0, 0, 0), "771,49199,65281-11-35-16", true},
+		{"truncated record", tlsRecords(tls12, 1<<14)[:60], "", false},
+		{"alert", []byte{0x15, 0x03, 0x03, 0x00, 0x02, 0x02, 0x28}, "", false},
+		{"client hello", tlsRecords(append([]byte{0x01}, tls12[1:]...), 1<<14), "", false},
+		{"plain text", []byte("HTTP/1.1 400 Bad Request\r\n\r\n"), "", false},
+		{"empty", nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseJA3S(tt.data)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("%s: parseJA3S = %q, %v, want %q, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package portsscanner
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lcvvvv/gonmap"
+)
+
+// 端口状态
+const (
+	StateOpen         = "open"
+	StateClosed       = "closed"
+	StateFiltered     = "filtered"
+	StateOpenFiltered = "open|filtered"
+)
+
+// 内置探针库未覆盖但常见的 UDP 服务载荷，取自 nmap-payloads
+var builtinUDPPayloads = map[int]struct {
+	service string
+	payload []byte
+}{
+	// IKE 主模式 SA 提议(3DES/SHA1/PSK/MODP1024)
+	500: {"isakmp", []byte("" +
+		"\x5b\x5e\x64\xc0\x3e\x99\xb5\x11\x00\x00\x00\x00\x00\x00\x00\x00" +
+		"\x01\x10\x02\x00\x00\x00\x00\x00\x00\x00\x00\x50" +
+		"\x00\x00\x00\x34\x00\x00\x00\x01\x00\x00\x00\x01" +
+		"\x00\x00\x00\x28\x01\x01\x00\x01" +
+		"\x00\x00\x00\x20\x01\x01\x00\x00" +
+		"\x80\x01\x00\x05\x80\x02\x00\x02\x80\x03\x00\x01" +
+		"\x80\x04\x00\x02\x80\x0b\x00\x01\x80\x0c\x70\x80")},
+	69:   {"tftp", []byte("\x00\x01r7tftp.txt\x00octet\x00")},
+	1900: {"upnp", []byte("M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\nMAN: \"ssdp:discover\"\r\nMX: 1\r\nST: ssdp:all\r\n\r\n")},
+}
+
+// 每个端口最多尝试的探针数，避免对单个端口发送过多载荷
+const maxUDPProbesPerPort = 5
+
+type udpProbe struct {
+	probe   *serviceProbe // 来自探针库时非空
+	service string        // 内置载荷对应的服务名
+	payload []byte
+}
+
+// udpPortState 向目标端口发送协议相关的 UDP 载荷并根据回应判断状态:
+// 收到数据为 open，收到 ICMP 端口不可达为 closed，超时无响应为 open|filtered
+func (s *portScanner) udpPortState(ctx context.Context, host string, port int) PortInfo {
+	portInfo := PortInfo{
+		Host:     host,
+		Port:     port,
+		Protocol: "udp",
+		State:    StateOpenFiltered,
+	}
+
+	db := udpProbeDB()
+	timing := s.timings.get(host)
+	for _, probe := range udpProbesForPort(db, port) {
+		select {
+		case <-ctx.Done():
+			portInfo.State = ""
+			return portInfo
+		default:
+		}
+
+		start := time.Now()
+		response, err := sendUDP(host, port, probe.payload, timing.Timeout())
+		if err != nil {
+			if isPortUnreachable(err) {
+				timing.Update(time.Since(start))
+				portInfo.State = StateClosed
+				return portInfo
+			}
+			continue
+		}
+
+		timing.Update(time.Since(start))
+		portInfo.State = StateOpen
+		if probe.probe != nil {
+			fillFingerPrint(&portInfo, db.match(probe.probe, response))
+		} else {
+			portInfo.Service = probe.service
+		}
+		if portInfo.Service == "" {
+			portInfo.Service = gonmap.GuessProtocol(port)
+		}
+		return portInfo
+	}
+
+	return portInfo
+}
+
+func udpProbesForPort(db *probeDB, port int) []udpProbe {
+	var probes []udpProbe
+	if builtin, ok := builtinUDPPayloads[port]; ok {
+		probes = append(probes, udpProbe{service: builtin.service, payload: builtin.payload})
+	}
+	for _, p := range db.probesForPort(port) {
+		if len(probes) >= maxUDPProbesPerPort {
+			break
+		}
+		probes = append(probes, udpProbe{probe: p, payload: p.payload})
+	}
+	if len(probes) == 0 {
+		// 无专用载荷时发送空包，仍可通过 ICMP 判断端口关闭
+		probes = append(probes, udpProbe{payload: []byte{}})
+	}
+	return probes
+}
+
+// sendUDP 使用已连接的 UDP 套接字发送载荷，ICMP 端口不可达会以读错误的形式返回
+func sendUDP(host string, port int, payload []byte, timeout time.Duration) ([]byte, error) {
+	conn, err := net.DialTimeout("udp", net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	if _, err := conn.Write(payload); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
+
+func isPortUnreachable(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+	// Windows 下表现为 WSAECONNRESET
+	msg := err.Error()
+	return strings.Contains(msg, "refused") || strings.Contains(msg, "forcibly closed")
+}
+
+// fillFingerPrint 将 gonmap 指纹写入端口信息
+func fillFingerPrint(portInfo *PortInfo, fp *gonmap.FingerPrint) {
+	if fp == nil {
+		return
+	}
+	portInfo.Service = fp.Service
+	portInfo.ProductName = fp.ProductName
+	portInfo.Version = fp.Version
+	portInfo.Info = fp.Info
+	portInfo.Hostname = fp.Hostname
+	portInfo.OperatingSystem = fp.OperatingSystem
+	portInfo.DeviceType = fp.DeviceType
+	portInfo.ProbeName = fp.ProbeName
+}
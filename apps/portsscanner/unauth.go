@@ -0,0 +1,94 @@
+package portsscanner
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// 风险等级
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityInfo     = "info"
+)
+
+// Finding 端口上发现的安全问题
+type Finding struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Severity    string `json:"severity"`
+	Evidence    string `json:"evidence"`    // 探测得到的证据
+	Remediation string `json:"remediation"` // 修复建议
+}
+
+// unauthProbe 发送只读请求判断服务是否允许未授权访问，可访问时返回证据，
+// 需要认证或不是目标服务时返回 false
+type unauthProbe func(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool)
+
+type unauthCheck struct {
+	id          string
+	name        string
+	severity    string
+	remediation string
+	probe       unauthProbe
+	// 基于 HTTP 的服务常被 gonmap 识别为 http/https，此时按默认端口匹配
+	httpPorts []int
+}
+
+// unauthChecks 按 gonmap 识别出的服务名索引
+var (
+	unauthChecks     = map[string][]*unauthCheck{}
+	unauthHTTPChecks []*unauthCheck
+)
+
+func registerUnauthCheck(check *unauthCheck, names ...string) {
+	for _, name := range names {
+		unauthChecks[name] = append(unauthChecks[name], check)
+	}
+	if len(check.httpPorts) > 0 {
+		unauthHTTPChecks = append(unauthHTTPChecks, check)
+	}
+}
+
+// matchUnauthChecks 返回适用于该端口的检查项
+func matchUnauthChecks(portInfo PortInfo) []*unauthCheck {
+	checks := unauthChecks[strings.ToLower(portInfo.Service)]
+	if !isHTTPService(portInfo) {
+		return checks
+	}
+	for _, check := range unauthHTTPChecks {
+		for _, port := range check.httpPorts {
+			if port == portInfo.Port {
+				checks = append(checks, check)
+				break
+			}
+		}
+	}
+	return checks
+}
+
+// checkUnauthorized 对端口执行适用的未授权访问检查，所有探测均为只读操作
+func checkUnauthorized(ctx context.Context, portInfo PortInfo, timeout time.Duration) []Finding {
+	var findings []Finding
+	seen := make(map[string]bool)
+	for _, check := range matchUnauthChecks(portInfo) {
+		if seen[check.id] || ctx.Err() != nil {
+			continue
+		}
+		seen[check.id] = true
+
+		if evidence, ok := check.probe(ctx, portInfo, timeout); ok {
+			findings = append(findings, Finding{
+				ID:          check.id,
+				Name:        check.name,
+				Severity:    check.severity,
+				Evidence:    evidence,
+				Remediation: check.remediation,
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,485 @@
+package portsscanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jlaffaye/ftp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// 证据中最多列出的条目数
+const maxEvidenceItems = 10
+
+// Redis INFO 响应的最大读取长度
+const maxRedisInfoSize = 64 << 10
+
+var errRedisAuth = errors.New("Redis 需要认证")
+
+func init() {
+	registerUnauthCheck(&unauthCheck{
+		id:          "redis-unauth",
+		name:        "Redis 未授权访问",
+		severity:    SeverityCritical,
+		remediation: "配置 requirepass 或 ACL 用户口令，绑定内网地址并开启 protected-mode，禁止以 root 运行",
+		probe:       probeRedisUnauth,
+	}, "redis")
+	registerUnauthCheck(&unauthCheck{
+		id:          "mongodb-unauth",
+		name:        "MongoDB 未授权访问",
+		severity:    SeverityHigh,
+		remediation: "启用 security.authorization 并为数据库创建账户，bindIp 仅监听内网地址",
+		probe:       probeMongoDBUnauth,
+	}, "mongodb")
+	registerUnauthCheck(&unauthCheck{
+		id:          "elasticsearch-unauth",
+		name:        "Elasticsearch 未授权访问",
+		severity:    SeverityHigh,
+		remediation: "启用 xpack.security 认证，或通过反向代理限制访问来源",
+		probe:       probeElasticsearchUnauth,
+		httpPorts:   []int{9200},
+	}, "elasticsearch")
+	registerUnauthCheck(&unauthCheck{
+		id:          "memcached-unauth",
+		name:        "Memcached 未授权访问",
+		severity:    SeverityMedium,
+		remediation: "使用 -l 仅监听内网地址并开启 SASL 认证，关闭 UDP 端口(-U 0)",
+		probe:       probeMemcachedUnauth,
+	}, "memcached", "memcache")
+	registerUnauthCheck(&unauthCheck{
+		id:          "docker-api-unauth",
+		name:        "Docker Remote API 未授权访问",
+		severity:    SeverityCritical,
+		remediation: "关闭 TCP 监听，或启用 TLS 客户端证书认证(--tlsverify)",
+		probe:       probeDockerUnauth,
+		httpPorts:   []int{2375, 2376},
+	}, "docker")
+	registerUnauthCheck(&unauthCheck{
+		id:          "kubelet-unauth",
+		name:        "Kubelet API 未授权访问",
+		severity:    SeverityCritical,
+		remediation: "设置 --anonymous-auth=false 与 --authorization-mode=Webhook，并关闭只读端口(--read-only-port=0)",
+		probe:       probeKubeletUnauth,
+		httpPorts:   []int{10250, 10255},
+	}, "kubelet")
+	registerUnauthCheck(&unauthCheck{
+		id:          "zookeeper-unauth",
+		name:        "ZooKeeper 未授权访问",
+		severity:    SeverityHigh,
+		remediation: "为 znode 设置 ACL 并启用 SASL 认证，限制 2181 端口的访问来源",
+		probe:       probeZooKeeperUnauth,
+	}, "zookeeper")
+	registerUnauthCheck(&unauthCheck{
+		id:          "rsync-unauth",
+		name:        "Rsync 未授权访问",
+		severity:    SeverityHigh,
+		remediation: "为模块配置 auth users 与 secrets file，并通过 hosts allow 限制来源",
+		probe:       probeRsyncUnauth,
+	}, "rsync")
+	registerUnauthCheck(&unauthCheck{
+		id:          "ftp-anonymous",
+		name:        "FTP 匿名登录",
+		severity:    SeverityMedium,
+		remediation: "关闭匿名登录(如 vsftpd 设置 anonymous_enable=NO)",
+		probe:       probeFTPAnonymous,
+	}, "ftp")
+}
+
+func probeRedisUnauth(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	body, err := redisInfo(ctx, portInfo, timeout, "server")
+	if err != nil {
+		return "", false
+	}
+
+	fields := infoFields(body, ":", "redis_version", "redis_mode", "os")
+	if len(fields) == 0 {
+		return "", false
+	}
+	return "INFO 命令返回 " + strings.Join(fields, ", "), true
+}
+
+// redisInfo 不带认证执行 INFO 命令，服务端要求认证或处于保护模式时返回 errRedisAuth
+func redisInfo(ctx context.Context, portInfo PortInfo, timeout time.Duration, sections ...string) (string, error) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := writeRESP(conn, append([]string{"INFO"}, sections...)...); err != nil {
+		return "", err
+	}
+	// 需要认证时返回 -NOAUTH，可访问时返回 $<长度> 的批量字符串
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(line, "-NOAUTH") || strings.HasPrefix(line, "-DENIED") {
+		return "", errRedisAuth
+	}
+	if !strings.HasPrefix(line, "$") {
+		return "", fmt.Errorf("非预期的响应: %q", strings.TrimSpace(line))
+	}
+	size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil || size <= 0 {
+		return "", fmt.Errorf("非预期的响应: %q", strings.TrimSpace(line))
+	}
+	body := make([]byte, min(size, maxRedisInfoSize))
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+func probeMongoDBUnauth(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	clientOptions := options.Client().
+		SetHosts([]string{net.JoinHostPort(portInfo.Host, strconv.Itoa(portInfo.Port))}).
+		SetDirect(true).
+		SetConnectTimeout(timeout).
+		SetServerSelectionTimeout(timeout)
+
+	client, err := mongo.Connect(ctx, clientOptions)
+	if err != nil {
+		return "", false
+	}
+	defer client.Disconnect(context.Background())
+
+	names, err := client.ListDatabaseNames(ctx, bson.D{})
+	if err != nil {
+		return "", false
+	}
+	return "无需认证即可列出数据库: " + joinLimited(names), true
+}
+
+func probeElasticsearchUnauth(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	status, body, err := unauthHTTPGet(ctx, portInfo, "/", timeout)
+	if err != nil || status != http.StatusOK {
+		return "", false
+	}
+	var info struct {
+		ClusterName string `json:"cluster_name"`
+		Version     struct {
+			Number string `json:"number"`
+		} `json:"version"`
+	}
+	if json.Unmarshal(body, &info) != nil || info.ClusterName == "" || info.Version.Number == "" {
+		return "", false
+	}
+
+	evidence := fmt.Sprintf("cluster_name=%s, version=%s", info.ClusterName, info.Version.Number)
+	if status, body, err := unauthHTTPGet(ctx, portInfo, "/_cat/indices?h=index", timeout); err == nil && status == http.StatusOK {
+		indices := strings.Fields(string(body))
+		evidence += fmt.Sprintf(", 索引 %d 个: %s", len(indices), joinLimited(indices))
+	}
+	return evidence, true
+}
+
+func probeMemcachedUnauth(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return "", false
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := conn.Write([]byte("stats\r\n")); err != nil {
+		return "", false
+	}
+	reply := readReply(conn, "END\r\n")
+	if !strings.Contains(reply, "STAT pid") {
+		return "", false
+	}
+	fields := infoFields(strings.ReplaceAll(reply, "STAT ", ""), " ", "version", "curr_items", "curr_connections")
+	return "stats 命令返回 " + strings.Join(fields, ", "), true
+}
+
+func probeDockerUnauth(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	status, body, err := unauthHTTPGet(ctx, portInfo, "/version", timeout)
+	if err != nil || status != http.StatusOK {
+		return "", false
+	}
+	var version struct {
+		Version    string `json:"Version"`
+		APIVersion string `json:"ApiVersion"`
+		Os         string `json:"Os"`
+		Arch       string `json:"Arch"`
+	}
+	if json.Unmarshal(body, &version) != nil || version.APIVersion == "" {
+		return "", false
+	}
+	return fmt.Sprintf("/version 返回 Docker %s (API %s) %s/%s", version.Version, version.APIVersion, version.Os, version.Arch), true
+}
+
+func probeKubeletUnauth(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	status, body, err := unauthHTTPGet(ctx, portInfo, "/pods", timeout)
+	if err != nil || status != http.StatusOK {
+		return "", false
+	}
+	var pods struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(body, &pods); err != nil {
+		// Pod 较多时响应体会被截断，只确认返回的是 Pod 列表
+		if bytes.Contains(body, []byte(`"kind":"PodList"`)) {
+			return "/pods 返回 Pod 列表", true
+		}
+		return "", false
+	}
+	if pods.Kind != "PodList" {
+		return "", false
+	}
+	return fmt.Sprintf("/pods 返回 %d 个 Pod 信息", len(pods.Items)), true
+}
+
+// ZooKeeper 协议常量
+const (
+	zkOpGetChildren = 8
+	zkMaxFrameSize  = 1 << 20
+)
+
+// probeZooKeeperUnauth 建立匿名会话并列出根节点的子节点
+func probeZooKeeperUnauth(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return "", false
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	// ConnectRequest: protocolVersion, lastZxidSeen, timeOut, sessionId, passwd
+	var req []byte
+	req = binary.BigEndian.AppendUint32(req, 0)
+	req = binary.BigEndian.AppendUint64(req, 0)
+	req = binary.BigEndian.AppendUint32(req, uint32(timeout.Milliseconds()))
+	req = binary.BigEndian.AppendUint64(req, 0)
+	req = binary.BigEndian.AppendUint32(req, 16)
+	req = append(req, make([]byte, 16)...)
+	resp, err := zkRoundTrip(conn, req)
+	// ConnectResponse 中协商的超时为 0 表示服务端拒绝了会话
+	if err != nil || len(resp) < 8 || binary.BigEndian.Uint32(resp[4:8]) == 0 {
+		return "", false
+	}
+
+	// GetChildrenRequest: xid, type, path, watch
+	req = binary.BigEndian.AppendUint32(req[:0], 1)
+	req = binary.BigEndian.AppendUint32(req, zkOpGetChildren)
+	req = binary.BigEndian.AppendUint32(req, 1)
+	req = append(req, '/', 0)
+	resp, err = zkRoundTrip(conn, req)
+	// ReplyHeader: xid, zxid, err，err 非 0(如 NoAuth)表示无权读取
+	if err != nil || len(resp) < 20 || binary.BigEndian.Uint32(resp[12:16]) != 0 {
+		return "", false
+	}
+
+	var children []string
+	count := int(int32(binary.BigEndian.Uint32(resp[16:20])))
+	data := resp[20:]
+	for i := 0; i < count && len(data) >= 4; i++ {
+		size := int(binary.BigEndian.Uint32(data[:4]))
+		if size > len(data)-4 {
+			break
+		}
+		children = append(children, "/"+string(data[4:4+size]))
+		data = data[4+size:]
+	}
+	return fmt.Sprintf("匿名会话可读取根节点，子节点 %d 个: %s", count, joinLimited(children)), true
+}
+
+// zkRoundTrip 发送一个带长度前缀的请求并读取一个响应帧
+func zkRoundTrip(conn net.Conn, req []byte) ([]byte, error) {
+	frame := binary.BigEndian.AppendUint32(nil, uint32(len(req)))
+	if _, err := conn.Write(append(frame, req...)); err != nil {
+		return nil, err
+	}
+
+	var header [4]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > zkMaxFrameSize {
+		return nil, fmt.Errorf("响应过大: %d", size)
+	}
+	resp := make([]byte, size)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// probeRsyncUnauth 列出模块并逐个确认是否需要认证，仅完成握手，不传输文件
+func probeRsyncUnauth(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	modules, err := rsyncRequest(ctx, portInfo, "", timeout)
+	if err != nil || len(modules) == 0 {
+		return "", false
+	}
+	if len(modules) > maxEvidenceItems {
+		modules = modules[:maxEvidenceItems]
+	}
+
+	var anonymous []string
+	for _, module := range modules {
+		if _, err := rsyncRequest(ctx, portInfo, module, timeout); err == nil {
+			anonymous = append(anonymous, module)
+		}
+	}
+	if len(anonymous) == 0 {
+		return "", false
+	}
+	return "无需认证即可访问模块: " + strings.Join(anonymous, ", "), true
+}
+
+var errRsyncAuthRequired = errors.New("模块需要认证")
+
+// rsyncRequest module 为空时列出模块名，否则请求该模块，服务端返回 OK 时说明无需认证
+func rsyncRequest(ctx context.Context, portInfo PortInfo, module string, timeout time.Duration) ([]string, error) {
+	conn, err := dialContext(ctx, portInfo.Host, portInfo.Port, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	reader := bufio.NewReader(conn)
+	greeting, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(greeting, "@RSYNCD:") {
+		return nil, fmt.Errorf("非 rsync 服务")
+	}
+	if _, err := fmt.Fprintf(conn, "@RSYNCD: 30.0\n%s\n", module); err != nil {
+		return nil, err
+	}
+
+	var modules []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return modules, err
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case module == "" && strings.HasPrefix(line, "@RSYNCD: EXIT"):
+			return modules, nil
+		case strings.HasPrefix(line, "@RSYNCD: OK"):
+			return nil, nil
+		case strings.HasPrefix(line, "@RSYNCD: AUTHREQD"):
+			return nil, errRsyncAuthRequired
+		case strings.HasPrefix(line, "@ERROR"):
+			return nil, errors.New(line)
+		case module == "" && line != "":
+			// 模块列表每行为 "名称\t说明"，MOTD 同样会出现在这里
+			if name := strings.Fields(line)[0]; !strings.HasPrefix(name, "@") {
+				modules = append(modules, name)
+			}
+		}
+	}
+}
+
+func probeFTPAnonymous(ctx context.Context, portInfo PortInfo, timeout time.Duration) (string, bool) {
+	conn, err := ftp.Dial(net.JoinHostPort(portInfo.Host, strconv.Itoa(portInfo.Port)), ftp.DialWithTimeout(timeout), ftp.DialWithContext(ctx))
+	if err != nil {
+		return "", false
+	}
+	defer conn.Quit()
+
+	if err := conn.Login("anonymous", "anonymous@example.com"); err != nil {
+		return "", false
+	}
+	evidence := "anonymous 用户登录成功"
+	// 数据连接可能被 NAT 或防火墙阻断，列目录失败不影响结论
+	if entries, err := conn.NameList("/"); err == nil {
+		evidence += fmt.Sprintf("，根目录 %d 个条目: %s", len(entries), joinLimited(entries))
+	}
+	return evidence, true
+}
+
+// unauthHTTPGet 不携带任何凭据请求 path，端口为 TLS 时使用 https
+func unauthHTTPGet(ctx context.Context, portInfo PortInfo, path string, timeout time.Duration) (int, []byte, error) {
+	scheme := "http"
+	if portInfo.TLS {
+		scheme = "https"
+	}
+	target := scheme + "://" + net.JoinHostPort(portInfo.Host, strconv.Itoa(portInfo.Port)) + path
+
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPBodySize))
+	return resp.StatusCode, body, err
+}
+
+// readReply 读取响应直到出现 terminator、连接关闭或超时
+func readReply(conn net.Conn, terminator string) string {
+	var reply bytes.Buffer
+	buf := make([]byte, 1024)
+	for reply.Len() < maxBannerSize {
+		n, err := conn.Read(buf)
+		reply.Write(buf[:n])
+		if err != nil || bytes.Contains(reply.Bytes(), []byte(terminator)) {
+			break
+		}
+	}
+	return reply.String()
+}
+
+// infoFields 从 "键<sep>值" 形式的多行文本中按顺序提取指定的键值
+func infoFields(text, sep string, keys ...string) []string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(text, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), sep); ok {
+			values[key] = value
+		}
+	}
+
+	var fields []string
+	for _, key := range keys {
+		if value, ok := values[key]; ok {
+			fields = append(fields, key+sep+value)
+		}
+	}
+	return fields
+}
+
+// joinLimited 拼接列表，超出部分以数量代替
+func joinLimited(items []string) string {
+	if len(items) <= maxEvidenceItems {
+		return strings.Join(items, ", ")
+	}
+	return fmt.Sprintf("%s 等 %d 项", strings.Join(items[:maxEvidenceItems], ", "), len(items))
+}
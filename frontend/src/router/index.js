@@ -3,11 +3,13 @@ import ScannerPortsView from '../views/ScannerPortsView.vue'
 import DirsearchView from '../views/DirsearchView.vue'
 import JsfinderView from '../views/JsfinderView.vue'
 import GitdorkerView from '../views/GitdorkerView.vue'
+import PocScanView from '../views/PocScanView.vue'
 const routes = [
   { path: '/', component: ScannerPortsView },
   { path: '/dirsearch', component: DirsearchView },
   { path: '/jsfinder', component: JsfinderView },
-  { path: '/gitdorker', component: GitdorkerView }
+  { path: '/gitdorker', component: GitdorkerView },
+  { path: '/pocscan', component: PocScanView }
 ]
 
 const router = createRouter({
@@ -0,0 +1,78 @@
+import { defineStore } from 'pinia'
+
+export const usePocscanStore = defineStore('pocscan', {
+  state: () => ({
+    targets: [],         // 待扫描目标，可由端口扫描与目录扫描结果导入
+    templatePath: '',
+    templates: [],       // 已加载的模板
+    templateErrors: [],  // 加载失败的模板
+    results: [],
+    current: 0,
+    total: 0,
+    isScanning: false,
+    scanStatus: 'idle',
+  }),
+
+  getters: {
+    scanProgress: (state) => {
+      if (state.total <= 0) return 0
+      return Math.min((state.current / state.total) * 100, 100)
+    },
+
+    severityCount: (state) => {
+      const count = {}
+      state.results.forEach(result => {
+        count[result.severity] = (count[result.severity] || 0) + 1
+      })
+      return count
+    }
+  },
+
+  actions: {
+    resetScan() {
+      this.results = []
+      this.current = 0
+      this.total = 0
+      this.isScanning = false
+      this.scanStatus = 'idle'
+    },
+
+    // 追加目标并去重，返回新增数量
+    addTargets(targets) {
+      let added = 0
+      targets.forEach(target => {
+        const value = (target || '').trim()
+        if (value && !this.targets.includes(value)) {
+          this.targets.push(value)
+          added++
+        }
+      })
+      return added
+    },
+
+    setTargets(targets) {
+      this.targets = []
+      this.addTargets(targets)
+    },
+
+    setTemplates(summary, path) {
+      this.templatePath = path
+      this.templates = summary?.templates || []
+      this.templateErrors = summary?.errors || []
+    },
+
+    addResult(result) {
+      this.results.push(result)
+    },
+
+    setProgress(progress) {
+      this.current = progress.current
+      this.total = progress.total
+    },
+
+    setScanStatus(status) {
+      this.scanStatus = status
+      this.isScanning = status === 'running'
+    }
+  }
+})
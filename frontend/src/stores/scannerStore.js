@@ -3,12 +3,40 @@ import { defineStore } from 'pinia'
 export const useScannerStore = defineStore('scanner', {
   state: () => ({
     openPorts: [],
+    aliveHosts: [],
     scannedPorts: 0,
+    scanRate: 0,
+    scanETA: 0,
     showProgress: false,
     scanComplete: false,
     target: '127.0.0.1',
-    startPort: 1,
-    endPort: 65535,
+    targetFile: '',
+    ipPrefer: '',      // 域名解析的协议族偏好: '' 双栈 / ipv4 / ipv6
+    importFile: '',  // 本次扫描导入的 nmap / masscan 结果
+    scanId: '',      // 当前扫描的 ID，用于过滤事件与停止扫描
+    ports: '1-65535',
+    excludeHosts: '',  // 排除的主机: IP、CIDR、范围、域名
+    excludeFile: '',
+    excludePorts: '',
+    scanType: 'connect',
+    skipDiscovery: false,
+    minTimeout: 100,
+    maxTimeout: 3000,
+    maxRetries: 1,
+    reportClosed: false,
+    reportFiltered: false,
+    stateCounts: null,
+    checkUnauth: true,
+    matchCVE: true,
+    cveDBInfo: null,
+    auditCredentials: false,
+    userFile: '',
+    passFile: '',
+    credentialThreads: 4,
+    credentialDelay: 0,
+    weakCredentials: [],
+    hostInfos: {},   // 主机 -> 被动识别的操作系统信息
+    totalPorts: 0,
     maxThreads: 500,
     isScanning: false
   }),
@@ -18,39 +46,155 @@ export const useScannerStore = defineStore('scanner', {
       if (state.scanComplete) return 100
       if (!state.showProgress) return 0
       
-      const total = state.endPort - state.startPort + 1
-      const scanned = state.scannedPorts - state.startPort + 1
-      return Math.min(Math.round((scanned / total) * 100), 99)
+      if (!state.totalPorts) return 0
+      return Math.min(Math.round((state.scannedPorts / state.totalPorts) * 100), 99)
     }
   },
   
   actions: {
     resetScan() {
       this.openPorts = []
+      this.aliveHosts = []
+      this.stateCounts = null
+      this.weakCredentials = []
+      this.hostInfos = {}
       this.scannedPorts = 0
+      this.scanRate = 0
+      this.scanETA = 0
+      this.totalPorts = 0
       this.showProgress = false
       this.scanComplete = false
       this.isScanning = false
     },
     
     setTarget(value) {
-      this.target = value || '127.0.0.1'
+      this.target = value || (this.targetFile ? '' : '127.0.0.1')
     },
     
-    setStartPort(value) {
-      const port = parseInt(value)
-      if (port >= 1 && port <= 65535) {
-        this.startPort = port
-      }
+    setTargetFile(value) {
+      this.targetFile = value || ''
+    },
+
+    setIPPrefer(value) {
+      this.ipPrefer = value || ''
+    },
+
+    setImportFile(value) {
+      this.importFile = value || ''
+    },
+
+    setScanId(value) {
+      this.scanId = value || ''
+    },
+
+    setPorts(value) {
+      this.ports = value || ''
+    },
+
+    setExcludeHosts(value) {
+      this.excludeHosts = value || ''
+    },
+
+    setExcludeFile(value) {
+      this.excludeFile = value || ''
+    },
+
+    setExcludePorts(value) {
+      this.excludePorts = value || ''
     },
     
-    setEndPort(value) {
-      const port = parseInt(value)
-      if (port >= 1 && port <= 65535) {
-        this.endPort = port
+    setScanType(value) {
+      this.scanType = value || 'connect'
+    },
+
+    setSkipDiscovery(value) {
+      this.skipDiscovery = !!value
+    },
+
+    setMinTimeout(value) {
+      const timeout = parseInt(value)
+      if (timeout >= 1 && timeout <= 60000) {
+        this.minTimeout = timeout
       }
     },
-    
+
+    setMaxTimeout(value) {
+      const timeout = parseInt(value)
+      if (timeout >= 1 && timeout <= 60000) {
+        this.maxTimeout = timeout
+      }
+    },
+
+    setMaxRetries(value) {
+      const retries = parseInt(value)
+      if (retries >= 0 && retries <= 10) {
+        this.maxRetries = retries
+      }
+    },
+
+    setReportClosed(value) {
+      this.reportClosed = !!value
+    },
+
+    setReportFiltered(value) {
+      this.reportFiltered = !!value
+    },
+
+    setStateCounts(value) {
+      this.stateCounts = value || null
+    },
+
+    setCheckUnauth(value) {
+      this.checkUnauth = !!value
+    },
+
+    setMatchCVE(value) {
+      this.matchCVE = !!value
+    },
+
+    setCVEDBInfo(value) {
+      this.cveDBInfo = value || null
+    },
+
+    setAuditCredentials(value) {
+      this.auditCredentials = !!value
+    },
+
+    setUserFile(value) {
+      this.userFile = value || ''
+    },
+
+    setPassFile(value) {
+      this.passFile = value || ''
+    },
+
+    setCredentialThreads(value) {
+      const threads = parseInt(value)
+      if (threads >= 1 && threads <= 32) {
+        this.credentialThreads = threads
+      }
+    },
+
+    setCredentialDelay(value) {
+      const delay = parseInt(value)
+      if (delay >= 0 && delay <= 60000) {
+        this.credentialDelay = delay
+      }
+    },
+
+    // 弱口令在端口扫描结束后才上报，同时记录到对应端口上
+    addWeakCredential(cred) {
+      this.weakCredentials.push(cred)
+      const port = this.openPorts.find(p => p.host === cred.host && p.port === cred.port)
+      if (port) {
+        port.credential = cred
+      }
+    },
+
+    setHostInfo(info) {
+      this.hostInfos[info.host] = info
+    },
+
     setMaxThreads(value) {
       const threads = parseInt(value)
       if (threads >= 1 && threads <= 1000) {
@@ -62,11 +206,17 @@ export const useScannerStore = defineStore('scanner', {
       this.isScanning = value
     },
     
+    addAliveHost(hostInfo) {
+      this.aliveHosts.push(hostInfo)
+    },
+
     addPort(portInfo) {
       // 扩展端口信息，包含所有指纹识别结果
       this.openPorts.push({
+        host: portInfo.host,
         port: portInfo.port,
         protocol: portInfo.protocol,
+        state: portInfo.state,
         service: portInfo.service,
         product_name: portInfo.product_name,
         version: portInfo.version,
@@ -75,17 +225,25 @@ export const useScannerStore = defineStore('scanner', {
         operating_system: portInfo.operating_system,
         device_type: portInfo.device_type,
         probe_name: portInfo.probe_name,
-        tls: portInfo.tls
+        tls: portInfo.tls,
+        banner: portInfo.banner || '',
+        certificate: portInfo.certificate || null,
+        http: portInfo.http || null,
+        details: portInfo.details || null,
+        cpe: portInfo.cpe || '',
+        cves: portInfo.cves || [],
+        findings: portInfo.findings || [],
+        credential: null
       })
-      // 按端口号排序
-      this.openPorts.sort((a, b) => a.port - b.port)
+      // 按主机、端口号排序
+      this.openPorts.sort((a, b) => a.host === b.host ? a.port - b.port : (a.host < b.host ? -1 : 1))
     },
     
     setScanComplete(value) {
       this.scanComplete = value
       if (value) {
         this.isScanning = false
-        this.scannedPorts = this.endPort
+        this.scannedPorts = this.totalPorts
       }
     },
     
@@ -96,18 +254,49 @@ export const useScannerStore = defineStore('scanner', {
     setScannedPorts(value) {
       this.scannedPorts = value
     },
+
+    setTotalPorts(value) {
+      this.totalPorts = value
+    },
+
+    // 后端按固定间隔推送的汇总进度
+    setProgress(progress) {
+      this.totalPorts = progress.total_ports
+      this.scannedPorts = progress.scanned
+      this.scanRate = progress.rate || 0
+      this.scanETA = progress.eta || 0
+    },
     
     clearAll() {
       this.resetScan()
       this.target = '127.0.0.1'
-      this.startPort = 1
-      this.endPort = 65535
+      this.targetFile = ''
+      this.ipPrefer = ''
+      this.importFile = ''
+      this.ports = '1-65535'
+      this.excludeHosts = ''
+      this.excludeFile = ''
+      this.excludePorts = ''
+      this.scanType = 'connect'
+      this.skipDiscovery = false
+      this.minTimeout = 100
+      this.maxTimeout = 3000
+      this.maxRetries = 1
+      this.reportClosed = false
+      this.reportFiltered = false
+      this.checkUnauth = true
+      this.matchCVE = true
+      this.auditCredentials = false
+      this.userFile = ''
+      this.passFile = ''
+      this.credentialThreads = 4
+      this.credentialDelay = 0
       this.maxThreads = 500
     },
 
     // 新增：检查端口是否有额外信息
     hasAdditionalInfo(port) {
-      return !!(port.hostname || port.operating_system || port.device_type || port.probe_name)
+      return !!(port.hostname || port.operating_system || port.device_type || port.probe_name || port.banner || port.certificate || port.http || port.credential || port.findings.length || port.cpe || port.details)
     },
 
     // 新增：获取端口的服务描述
@@ -122,16 +311,26 @@ export const useScannerStore = defineStore('scanner', {
     // 新增：导出扫描结果
     exportResults() {
       return this.openPorts.map(port => ({
+        host: port.host,
         port: port.port,
         service: this.getServiceDescription(port),
         details: {
           protocol: port.protocol,
+          state: port.state,
           tls: port.tls,
           info: port.info,
           hostname: port.hostname,
           operating_system: port.operating_system,
           device_type: port.device_type,
-          probe_name: port.probe_name
+          probe_name: port.probe_name,
+          banner: port.banner,
+          certificate: port.certificate,
+          http: port.http,
+          service_details: port.details,
+          credential: port.credential,
+          cpe: port.cpe,
+          cves: port.cves,
+          findings: port.findings
         }
       }))
     }
@@ -21,11 +21,572 @@ export namespace gitdorker {
 
 }
 
+export namespace pocscan {
+	
+	export class PocScanOptions {
+	    template_path: string;
+	    targets: string[];
+	    threads: number;
+	    timeout: number;
+	    severities: string[];
+	    tags: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PocScanOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.template_path = source["template_path"];
+	        this.targets = source["targets"];
+	        this.threads = source["threads"];
+	        this.timeout = source["timeout"];
+	        this.severities = source["severities"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class TemplateInfo {
+	    name: string;
+	    author: string[];
+	    severity: string;
+	    description: string;
+	    reference: string[];
+	    tags: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.author = source["author"];
+	        this.severity = source["severity"];
+	        this.description = source["description"];
+	        this.reference = source["reference"];
+	        this.tags = source["tags"];
+	    }
+	}
+	export class Template {
+	    id: string;
+	    info: TemplateInfo;
+	    path: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Template(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.info = this.convertValues(source["info"], TemplateInfo);
+	        this.path = source["path"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TemplateSummary {
+	    templates: Template[];
+	    errors: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TemplateSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.templates = this.convertValues(source["templates"], Template);
+	        this.errors = source["errors"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
 export namespace portsscanner {
 	
+	export class CVEDatabaseInfo {
+	    path: string;
+	    cves: number;
+	    products: number;
+	    // Go type: time
+	    updated: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new CVEDatabaseInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.cves = source["cves"];
+	        this.products = source["products"];
+	        this.updated = this.convertValues(source["updated"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class CVEMatch {
+	    id: string;
+	    cvss: number;
+	    severity: string;
+	    evidence: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CVEMatch(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.cvss = source["cvss"];
+	        this.severity = source["severity"];
+	        this.evidence = source["evidence"];
+	    }
+	}
+	export class CheckpointSummary {
+	    id: string;
+	    target: string;
+	    port_spec: string;
+	    scan_type: string;
+	    import_file?: string;
+	    total: number;
+	    scanned: number;
+	    open_ports: number;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new CheckpointSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.target = source["target"];
+	        this.port_spec = source["port_spec"];
+	        this.scan_type = source["scan_type"];
+	        this.import_file = source["import_file"];
+	        this.total = source["total"];
+	        this.scanned = source["scanned"];
+	        this.open_ports = source["open_ports"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Finding {
+	    id: string;
+	    name: string;
+	    severity: string;
+	    evidence: string;
+	    remediation: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Finding(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.severity = source["severity"];
+	        this.evidence = source["evidence"];
+	        this.remediation = source["remediation"];
+	    }
+	}
+	export class HTTPInfo {
+	    url: string;
+	    status_code: number;
+	    title: string;
+	    server?: string;
+	    powered_by?: string;
+	    content_type?: string;
+	    content_length: number;
+	    redirects?: string[];
+	    favicon_url?: string;
+	    favicon_hash?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HTTPInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.url = source["url"];
+	        this.status_code = source["status_code"];
+	        this.title = source["title"];
+	        this.server = source["server"];
+	        this.powered_by = source["powered_by"];
+	        this.content_type = source["content_type"];
+	        this.content_length = source["content_length"];
+	        this.redirects = source["redirects"];
+	        this.favicon_url = source["favicon_url"];
+	        this.favicon_hash = source["favicon_hash"];
+	    }
+	}
+	export class HostOSInfo {
+	    host: string;
+	    os: string;
+	    confidence: number;
+	    initial_ttl: number;
+	    distance: number;
+	    window: number;
+	    options: string;
+	    df: boolean;
+	    syn_acks: number;
+	    resets: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new HostOSInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.os = source["os"];
+	        this.confidence = source["confidence"];
+	        this.initial_ttl = source["initial_ttl"];
+	        this.distance = source["distance"];
+	        this.window = source["window"];
+	        this.options = source["options"];
+	        this.df = source["df"];
+	        this.syn_acks = source["syn_acks"];
+	        this.resets = source["resets"];
+	    }
+	}
+	export class PortChange {
+	    host: string;
+	    port: number;
+	    protocol: string;
+	    change: string;
+	    fields?: string[];
+	    before: PortInfo;
+	    after: PortInfo;
+	
+	    static createFrom(source: any = {}) {
+	        return new PortChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.port = source["port"];
+	        this.protocol = source["protocol"];
+	        this.change = source["change"];
+	        this.fields = source["fields"];
+	        this.before = this.convertValues(source["before"], PortInfo);
+	        this.after = this.convertValues(source["after"], PortInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class PortInfo {
+	    host: string;
+	    port: number;
+	    protocol: string;
+	    state: string;
+	    service: string;
+	    product_name: string;
+	    version: string;
+	    info: string;
+	    hostname: string;
+	    operating_system: string;
+	    device_type: string;
+	    probe_name: string;
+	    tls: boolean;
+	    banner?: string;
+	    certificate: TLSInfo;
+	    http: HTTPInfo;
+	    details?: {[key: string]: any};
+	    cpe?: string;
+	    cves: CVEMatch[];
+	    findings: Finding[];
+	
+	    static createFrom(source: any = {}) {
+	        return new PortInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.port = source["port"];
+	        this.protocol = source["protocol"];
+	        this.state = source["state"];
+	        this.service = source["service"];
+	        this.product_name = source["product_name"];
+	        this.version = source["version"];
+	        this.info = source["info"];
+	        this.hostname = source["hostname"];
+	        this.operating_system = source["operating_system"];
+	        this.device_type = source["device_type"];
+	        this.probe_name = source["probe_name"];
+	        this.tls = source["tls"];
+	        this.banner = source["banner"];
+	        this.certificate = this.convertValues(source["certificate"], TLSInfo);
+	        this.http = this.convertValues(source["http"], HTTPInfo);
+	        this.details = source["details"];
+	        this.cpe = source["cpe"];
+	        this.cves = this.convertValues(source["cves"], CVEMatch);
+	        this.findings = this.convertValues(source["findings"], Finding);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProbeFileInfo {
+	    name: string;
+	    path: string;
+	    format: string;
+	    probes: number;
+	    matches: number;
+	    errors: ProbeParseError[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProbeFileInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.format = source["format"];
+	        this.probes = source["probes"];
+	        this.matches = source["matches"];
+	        this.errors = this.convertValues(source["errors"], ProbeParseError);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ProbeParseError {
+	    line: number;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ProbeParseError(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.line = source["line"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ScanDiff {
+	    base: ScanSummary;
+	    compare: ScanSummary;
+	    added: PortChange[];
+	    removed: PortChange[];
+	    changed: PortChange[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanDiff(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.base = this.convertValues(source["base"], ScanSummary);
+	        this.compare = this.convertValues(source["compare"], ScanSummary);
+	        this.added = this.convertValues(source["added"], PortChange);
+	        this.removed = this.convertValues(source["removed"], PortChange);
+	        this.changed = this.convertValues(source["changed"], PortChange);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScanOptions {
+	    target: string;
+	    target_file: string;
+	    ip_prefer: string;
+	    import_file: string;
+	    ports: string;
+	    scan_type: string;
+	    max_threads: number;
+	    exclude_hosts: string;
+	    exclude_file: string;
+	    exclude_ports: string;
+	    skip_discovery: boolean;
+	    min_timeout: number;
+	    max_timeout: number;
+	    max_retries: number;
+	    report_closed: boolean;
+	    report_filtered: boolean;
+	    check_unauth: boolean;
+	    match_cve: boolean;
+	    audit_credentials: boolean;
+	    user_file: string;
+	    pass_file: string;
+	    credential_threads: number;
+	    credential_delay: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.target = source["target"];
+	        this.target_file = source["target_file"];
+	        this.ip_prefer = source["ip_prefer"];
+	        this.import_file = source["import_file"];
+	        this.ports = source["ports"];
+	        this.scan_type = source["scan_type"];
+	        this.max_threads = source["max_threads"];
+	        this.exclude_hosts = source["exclude_hosts"];
+	        this.exclude_file = source["exclude_file"];
+	        this.exclude_ports = source["exclude_ports"];
+	        this.skip_discovery = source["skip_discovery"];
+	        this.min_timeout = source["min_timeout"];
+	        this.max_timeout = source["max_timeout"];
+	        this.max_retries = source["max_retries"];
+	        this.report_closed = source["report_closed"];
+	        this.report_filtered = source["report_filtered"];
+	        this.check_unauth = source["check_unauth"];
+	        this.match_cve = source["match_cve"];
+	        this.audit_credentials = source["audit_credentials"];
+	        this.user_file = source["user_file"];
+	        this.pass_file = source["pass_file"];
+	        this.credential_threads = source["credential_threads"];
+	        this.credential_delay = source["credential_delay"];
+	    }
+	}
 	export class ScanProgress {
-	    current_port: number;
+	    scanned: number;
 	    total_ports: number;
+	    open: number;
+	    rate: number;
+	    eta: number;
 	    status: string;
 	
 	    static createFrom(source: any = {}) {
@@ -34,11 +595,198 @@ export namespace portsscanner {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.current_port = source["current_port"];
+	        this.scanned = source["scanned"];
 	        this.total_ports = source["total_ports"];
+	        this.open = source["open"];
+	        this.rate = source["rate"];
+	        this.eta = source["eta"];
 	        this.status = source["status"];
 	    }
 	}
+	export class ScanRecord {
+	    id: string;
+	    target: string;
+	    port_spec: string;
+	    scan_type: string;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    finished_at: any;
+	    hosts: number;
+	    open_ports: number;
+	    targets: string[];
+	    stats: ScanStats;
+	    ports: PortInfo[];
+	    host_info?: HostOSInfo[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanRecord(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.target = source["target"];
+	        this.port_spec = source["port_spec"];
+	        this.scan_type = source["scan_type"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.finished_at = this.convertValues(source["finished_at"], null);
+	        this.hosts = source["hosts"];
+	        this.open_ports = source["open_ports"];
+	        this.targets = source["targets"];
+	        this.stats = this.convertValues(source["stats"], ScanStats);
+	        this.ports = this.convertValues(source["ports"], PortInfo);
+	        this.host_info = this.convertValues(source["host_info"], HostOSInfo);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScanStats {
+	    open: number;
+	    closed: number;
+	    filtered: number;
+	    open_filtered: number;
+	    excluded: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.open = source["open"];
+	        this.closed = source["closed"];
+	        this.filtered = source["filtered"];
+	        this.open_filtered = source["open_filtered"];
+	        this.excluded = source["excluded"];
+	    }
+	}
+	export class ScanSummary {
+	    id: string;
+	    target: string;
+	    port_spec: string;
+	    scan_type: string;
+	    // Go type: time
+	    started_at: any;
+	    // Go type: time
+	    finished_at: any;
+	    hosts: number;
+	    open_ports: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScanSummary(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.target = source["target"];
+	        this.port_spec = source["port_spec"];
+	        this.scan_type = source["scan_type"];
+	        this.started_at = this.convertValues(source["started_at"], null);
+	        this.finished_at = this.convertValues(source["finished_at"], null);
+	        this.hosts = source["hosts"];
+	        this.open_ports = source["open_ports"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TLSInfo {
+	    subject: string;
+	    common_name: string;
+	    sans?: string[];
+	    issuer: string;
+	    serial_number: string;
+	    // Go type: time
+	    not_before: any;
+	    // Go type: time
+	    not_after: any;
+	    key_type: string;
+	    key_size: number;
+	    version: string;
+	    cipher_suite: string;
+	    alpn?: string;
+	    ja3s: string;
+	    ja3s_hash: string;
+	    expired: boolean;
+	    self_signed: boolean;
+	    hostname_mismatch: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new TLSInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.subject = source["subject"];
+	        this.common_name = source["common_name"];
+	        this.sans = source["sans"];
+	        this.issuer = source["issuer"];
+	        this.serial_number = source["serial_number"];
+	        this.not_before = this.convertValues(source["not_before"], null);
+	        this.not_after = this.convertValues(source["not_after"], null);
+	        this.key_type = source["key_type"];
+	        this.key_size = source["key_size"];
+	        this.version = source["version"];
+	        this.cipher_suite = source["cipher_suite"];
+	        this.alpn = source["alpn"];
+	        this.ja3s = source["ja3s"];
+	        this.ja3s_hash = source["ja3s_hash"];
+	        this.expired = source["expired"];
+	        this.self_signed = source["self_signed"];
+	        this.hostname_mismatch = source["hostname_mismatch"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 
 }
 
@@ -3,12 +3,58 @@
 import {portsscanner} from '../models';
 import {context} from '../models';
 
-export function GetScanProgress():Promise<portsscanner.ScanProgress>;
+export function AddProbeFile(arg1:string):Promise<portsscanner.ProbeFileInfo>;
 
-export function GetScanStatus():Promise<string>;
+export function CanSYNScan():Promise<boolean>;
 
-export function ScanPorts(arg1:string,arg2:number,arg3:number,arg4:number):Promise<void>;
+export function DeleteScanCheckpoint(arg1:string):Promise<void>;
+
+export function DeleteScanRecord(arg1:string):Promise<void>;
+
+export function DiffScans(arg1:string,arg2:string):Promise<portsscanner.ScanDiff>;
+
+export function ExportResults(arg1:Array<portsscanner.PortInfo>,arg2:portsscanner.ScanOptions):Promise<string>;
+
+export function ExportScanDiff(arg1:string,arg2:string):Promise<string>;
+
+export function ExportScanRecord(arg1:string):Promise<string>;
+
+export function GetCVEDatabaseInfo():Promise<portsscanner.CVEDatabaseInfo>;
+
+export function GetScanProgress(arg1:string):Promise<portsscanner.ScanProgress>;
+
+export function GetScanRecord(arg1:string):Promise<portsscanner.ScanRecord>;
+
+export function GetScanStatus(arg1:string):Promise<string>;
+
+export function ImportCVEFeeds():Promise<portsscanner.CVEDatabaseInfo>;
+
+export function ListProbeFiles():Promise<Array<portsscanner.ProbeFileInfo>>;
+
+export function ListScanCheckpoints():Promise<Array<portsscanner.CheckpointSummary>>;
+
+export function ListScanHistory(arg1:string):Promise<Array<portsscanner.ScanSummary>>;
+
+export function ListScans():Promise<Array<string>>;
+
+export function OpenExcludeFileDialog():Promise<string>;
+
+export function OpenImportFileDialog():Promise<string>;
+
+export function OpenProbeFileDialog():Promise<string>;
+
+export function OpenTargetFileDialog():Promise<string>;
+
+export function OpenWordListDialog():Promise<string>;
+
+export function RemoveProbeFile(arg1:string):Promise<void>;
+
+export function ResumeScan(arg1:string):Promise<void>;
+
+export function ScanPorts(arg1:portsscanner.ScanOptions):Promise<string>;
 
 export function Startup(arg1:context.Context):Promise<void>;
 
-export function StopScan():Promise<void>;
+export function StopScan(arg1:string):Promise<void>;
+
+export function ValidateProbeFile(arg1:string):Promise<portsscanner.ProbeFileInfo>;
@@ -2,22 +2,114 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
-export function GetScanProgress() {
-  return window['go']['portsscanner']['App']['GetScanProgress']();
+export function AddProbeFile(arg1) {
+  return window['go']['portsscanner']['App']['AddProbeFile'](arg1);
 }
 
-export function GetScanStatus() {
-  return window['go']['portsscanner']['App']['GetScanStatus']();
+export function CanSYNScan() {
+  return window['go']['portsscanner']['App']['CanSYNScan']();
 }
 
-export function ScanPorts(arg1, arg2, arg3, arg4) {
-  return window['go']['portsscanner']['App']['ScanPorts'](arg1, arg2, arg3, arg4);
+export function DeleteScanCheckpoint(arg1) {
+  return window['go']['portsscanner']['App']['DeleteScanCheckpoint'](arg1);
+}
+
+export function DeleteScanRecord(arg1) {
+  return window['go']['portsscanner']['App']['DeleteScanRecord'](arg1);
+}
+
+export function DiffScans(arg1, arg2) {
+  return window['go']['portsscanner']['App']['DiffScans'](arg1, arg2);
+}
+
+export function ExportResults(arg1, arg2) {
+  return window['go']['portsscanner']['App']['ExportResults'](arg1, arg2);
+}
+
+export function ExportScanDiff(arg1, arg2) {
+  return window['go']['portsscanner']['App']['ExportScanDiff'](arg1, arg2);
+}
+
+export function ExportScanRecord(arg1) {
+  return window['go']['portsscanner']['App']['ExportScanRecord'](arg1);
+}
+
+export function GetCVEDatabaseInfo() {
+  return window['go']['portsscanner']['App']['GetCVEDatabaseInfo']();
+}
+
+export function GetScanProgress(arg1) {
+  return window['go']['portsscanner']['App']['GetScanProgress'](arg1);
+}
+
+export function GetScanRecord(arg1) {
+  return window['go']['portsscanner']['App']['GetScanRecord'](arg1);
+}
+
+export function GetScanStatus(arg1) {
+  return window['go']['portsscanner']['App']['GetScanStatus'](arg1);
+}
+
+export function ImportCVEFeeds() {
+  return window['go']['portsscanner']['App']['ImportCVEFeeds']();
+}
+
+export function ListProbeFiles() {
+  return window['go']['portsscanner']['App']['ListProbeFiles']();
+}
+
+export function ListScanCheckpoints() {
+  return window['go']['portsscanner']['App']['ListScanCheckpoints']();
+}
+
+export function ListScanHistory(arg1) {
+  return window['go']['portsscanner']['App']['ListScanHistory'](arg1);
+}
+
+export function ListScans() {
+  return window['go']['portsscanner']['App']['ListScans']();
+}
+
+export function OpenExcludeFileDialog() {
+  return window['go']['portsscanner']['App']['OpenExcludeFileDialog']();
+}
+
+export function OpenImportFileDialog() {
+  return window['go']['portsscanner']['App']['OpenImportFileDialog']();
+}
+
+export function OpenProbeFileDialog() {
+  return window['go']['portsscanner']['App']['OpenProbeFileDialog']();
+}
+
+export function OpenTargetFileDialog() {
+  return window['go']['portsscanner']['App']['OpenTargetFileDialog']();
+}
+
+export function OpenWordListDialog() {
+  return window['go']['portsscanner']['App']['OpenWordListDialog']();
+}
+
+export function RemoveProbeFile(arg1) {
+  return window['go']['portsscanner']['App']['RemoveProbeFile'](arg1);
+}
+
+export function ResumeScan(arg1) {
+  return window['go']['portsscanner']['App']['ResumeScan'](arg1);
+}
+
+export function ScanPorts(arg1) {
+  return window['go']['portsscanner']['App']['ScanPorts'](arg1);
 }
 
 export function Startup(arg1) {
   return window['go']['portsscanner']['App']['Startup'](arg1);
 }
 
-export function StopScan() {
-  return window['go']['portsscanner']['App']['StopScan']();
+export function StopScan(arg1) {
+  return window['go']['portsscanner']['App']['StopScan'](arg1);
+}
+
+export function ValidateProbeFile(arg1) {
+  return window['go']['portsscanner']['App']['ValidateProbeFile'](arg1);
 }